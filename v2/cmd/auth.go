@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect authentication",
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the current authentication token",
+	Long: `
+DESCRIPTION
+
+  Show the type of the token that is used to authenticate, when it expires and its scopes, if they are known.
+  The token is refreshed if required, and the server is then contacted to check which user it authenticates.
+
+  OAuth2 tokens are refreshed when they expire within the delay that is defined by the --refresh_margin flag,
+  and they are also refreshed in the background during long transfers.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` auth status
+  ` + os.Args[0] + ` auth status --profile prod
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		c, err := resolveConfig()
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Fatalf("No configuration has been found, please make sure to run '%s configure' first.\n", os.Args[0])
+			}
+			log.Fatal(err)
+		}
+
+		_, refreshErr := rest.RefreshAndStoreIfRequired(&c)
+		rest.DefaultConfig = &c
+		st := rest.GetTokenStatus(&c)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		table.Append([]string{"Profile", c.Label})
+		table.Append([]string{"URL", c.Url})
+		table.Append([]string{"Token type", st.AuthType})
+		if st.ExpiresAt.IsZero() {
+			table.Append([]string{"Expires at", "unknown"})
+		} else {
+			table.Append([]string{"Expires at", st.ExpiresAt.Format(time.RFC3339)})
+			if remaining := st.Remaining(); remaining > 0 {
+				table.Append([]string{"Remaining", remaining.Round(time.Second).String()})
+			} else {
+				table.Append([]string{"Remaining", "expired"})
+			}
+		}
+		if st.HasRefreshToken {
+			table.Append([]string{"Refresh", "automatic, " + rest.RefreshMargin.String() + " before expiration"})
+		}
+		if len(st.Scopes) > 0 {
+			table.Append([]string{"Scopes", strings.Join(st.Scopes, " ")})
+		} else {
+			table.Append([]string{"Scopes", "unknown"})
+		}
+		if refreshErr != nil {
+			table.Append([]string{"User", "unknown, " + refreshErr.Error()})
+		} else if user, e := rest.RetrieveCurrentSessionLogin(); e != nil {
+			table.Append([]string{"User", "unknown, " + e.Error()})
+		} else {
+			table.Append([]string{"User", user})
+		}
+		table.Render()
+
+		if refreshErr != nil {
+			fmt.Printf("%s %s\n", promptui.IconBad, refreshErr.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+	RootCmd.AddCommand(authCmd)
+}
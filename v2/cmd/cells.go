@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	cellName        string
+	cellDescription string
+	cellRoots       []string
+	cellMembers     []string
+	cellForce       bool
+)
+
+var cellCmd = &cobra.Command{
+	Use:   "cell",
+	Short: "Manage your cells",
+	Long: `
+DESCRIPTION
+
+  Create and delete cells, and manage their members.
+  A cell is a collaboration space: it gives access to a set of files and folders to a list of users, groups or roles.
+
+MEMBERS
+
+  Members are defined with the kind:id[:rights] syntax, where:
+   - kind is one of 'user', 'group' or 'role',
+   - id is the login of a user, the full path of a group (e.g: /engineering/backend) or the UUID of a role,
+   - rights is one of 'r' (read only), 'w' (write only) or 'rw' (read and write, the default).
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var cellCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a new cell",
+	Long: `
+DESCRIPTION
+
+  Create a new cell and share it with the passed members. You are always added as a member with full rights.
+  If no root is given, the cell is created with an empty root folder.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` cell create --name "Project X" --root common-files/project-x --member user:alice:rw --member group:/engineering:r
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		if cellName == "" {
+			log.Fatal("Please provide a name for the cell with the --name flag")
+		}
+
+		cell := &models.RestCell{
+			Label:                   cellName,
+			Description:             cellDescription,
+			ACLs:                    map[string]models.RestCellACL{},
+			PoliciesContextEditable: true,
+		}
+		for _, r := range cellRoots {
+			node, exists := rest.StatNode(strings.Trim(r, "/"))
+			if !exists {
+				log.Fatalf("No node found at %s", r)
+			}
+			cell.RootNodes = append(cell.RootNodes, &models.TreeNode{UUID: node.UUID})
+		}
+
+		members := cellMembers
+		if login := rest.DefaultConfig.User; login != "" && !hasUserMember(members, login) {
+			members = append(members, rest.MemberUser+":"+login+":rw")
+		}
+		for _, def := range members {
+			acl := resolveCellMember(def)
+			cell.ACLs[acl.RoleID] = *acl
+		}
+
+		created, err := rest.PutCell(cell)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Cell %s has been created with ID %s\n", created.Label, created.UUID)
+	},
+}
+
+var cellLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your cells",
+	Args:  cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		cells, err := rest.ListCells()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if printList(cells) {
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Uuid", "Slug", "Label", "Description", "Permissions"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, c := range cells {
+			table.Append([]string{c.UUID, c.Slug, c.Label, c.Description, c.Permissions})
+		}
+		fmt.Printf("Found %d cells\n", len(cells))
+		table.Render()
+	},
+}
+
+var cellShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the roots and members of a cell",
+	Long: `
+DESCRIPTION
+
+  Show the shared resources and the members of a cell, given its UUID, slug or label.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		cell, err := rest.FindCell(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Cell %s (%s)\n", cell.Label, cell.UUID)
+		if cell.Description != "" {
+			fmt.Println(cell.Description)
+		}
+		for _, n := range cell.RootNodes {
+			fmt.Printf("  - Shared resource: %s\n", strings.Trim(n.Path, "/"))
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Kind", "Member", "Rights"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, acl := range cell.ACLs {
+			acl := acl
+			kind, id := rest.CellAclMember(&acl)
+			table.Append([]string{kind, id, rest.CellAclRights(&acl)})
+		}
+		table.Render()
+	},
+}
+
+var cellAddMemberCmd = &cobra.Command{
+	Use:   "add-member",
+	Short: "Add members to a cell or change their rights",
+	Long: `
+DESCRIPTION
+
+  Add one or more members to a cell, given its UUID, slug or label.
+  If a member already has access to the cell, its rights are replaced.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` cell add-member "Project X" user:bob:r group:/marketing:rw
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cm *cobra.Command, args []string) {
+		cell, err := rest.FindCell(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cell.ACLs == nil {
+			cell.ACLs = map[string]models.RestCellACL{}
+		}
+		for _, def := range args[1:] {
+			acl := resolveCellMember(def)
+			cell.ACLs[acl.RoleID] = *acl
+		}
+		if _, err = rest.PutCell(cell); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d members have been added to %s\n", len(args)-1, cell.Label)
+	},
+}
+
+var cellRmMemberCmd = &cobra.Command{
+	Use:   "rm-member",
+	Short: "Remove members from a cell",
+	Long: `
+DESCRIPTION
+
+  Remove one or more members from a cell, given its UUID, slug or label.
+  Members are given with the kind:id syntax, rights are ignored.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` cell rm-member "Project X" user:bob group:/marketing
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cm *cobra.Command, args []string) {
+		cell, err := rest.FindCell(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, def := range args[1:] {
+			acl := resolveCellMember(def)
+			if _, ok := cell.ACLs[acl.RoleID]; !ok {
+				log.Fatalf("%s is not a member of %s", def, cell.Label)
+			}
+			delete(cell.ACLs, acl.RoleID)
+		}
+		if len(cell.ACLs) == 0 {
+			log.Fatal("A cell must keep at least one member, use 'cell delete' to remove the cell")
+		}
+		if _, err = rest.PutCell(cell); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d members have been removed from %s\n", len(args)-1, cell.Label)
+	},
+}
+
+var cellDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a cell",
+	Long: `
+DESCRIPTION
+
+  Delete a cell, given its UUID, slug or label. The shared files and folders are left untouched,
+  unless the cell has been created with an empty root folder: in such case, its content is lost.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		cell, err := rest.FindCell(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !cellForce {
+			p := promptui.Select{Label: fmt.Sprintf("Delete cell %s", cell.Label), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+		if err = rest.DeleteCell(cell.UUID); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Cell %s has been deleted\n", cell.Label)
+	},
+}
+
+func resolveCellMember(def string) *models.RestCellACL {
+	m, err := rest.ParseCellMember(def)
+	if err != nil {
+		log.Fatal(err)
+	}
+	acl, err := m.ToCellAcl()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return acl
+}
+
+func hasUserMember(members []string, login string) bool {
+	for _, def := range members {
+		if m, err := rest.ParseCellMember(def); err == nil && m.Kind == rest.MemberUser && m.ID == login {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	cellCreateCmd.Flags().StringVar(&cellName, "name", "", "Name of the cell")
+	cellCreateCmd.Flags().StringVar(&cellDescription, "description", "", "Description of the cell")
+	cellCreateCmd.Flags().StringArrayVar(&cellRoots, "root", []string{}, "Path of a file or folder to share in the cell, can be repeated")
+	cellCreateCmd.Flags().StringArrayVar(&cellMembers, "member", []string{}, "Member of the cell with the kind:id[:rights] syntax, can be repeated")
+	cellDeleteCmd.Flags().BoolVarP(&cellForce, "force", "f", false, "Do not ask for user approval")
+
+	cellCmd.AddCommand(cellCreateCmd)
+	cellCmd.AddCommand(cellLsCmd)
+	cellCmd.AddCommand(cellShowCmd)
+	cellCmd.AddCommand(cellAddMemberCmd)
+	cellCmd.AddCommand(cellRmMemberCmd)
+	cellCmd.AddCommand(cellDeleteCmd)
+	RootCmd.AddCommand(cellCmd)
+}
@@ -21,6 +21,7 @@ DESCRIPTION
 	
 	It deletes the ` + confFileName + ` from Cells Client working directory.
 	It also removes the sensitive data that has been stored in the keyring, if present.
+	Tokens of OAuth2 profiles are first revoked on the server, unless the --local-only flag is set.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -53,6 +54,9 @@ func ClearConfig() error {
 	}
 
 	for id, conf := range configs.Configs {
+		if err = revokeProfile(conf); err != nil {
+			return fmt.Errorf("could not revoke tokens for %s: %s", id, err.Error())
+		}
 		if !conf.SkipKeyring {
 			err = rest.ClearKeyring(conf)
 			if err != nil {
@@ -69,4 +73,5 @@ func ClearConfig() error {
 func init() {
 	RootCmd.AddCommand(clearCmd)
 	clearCmd.Flags().BoolVarP(&force, "force", "f", false, "Non interactive way to clear")
+	clearCmd.Flags().BoolVar(&localOnly, "local-only", false, "Do not revoke the tokens on the server, only remove local information")
 }
@@ -20,6 +20,16 @@ var configCmd = &cobra.Command{
 	},
 }
 
+// profileSummary is the structured output of the config ls command.
+type profileSummary struct {
+	ID       string `json:"id" yaml:"id"`
+	Active   bool   `json:"active" yaml:"active"`
+	Label    string `json:"label" yaml:"label"`
+	User     string `json:"user,omitempty" yaml:"user,omitempty"`
+	URL      string `json:"url" yaml:"url"`
+	AuthType string `json:"authType" yaml:"authType"`
+}
+
 var configListCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List the current authentication profiles",
@@ -30,11 +40,6 @@ var configListCmd = &cobra.Command{
 			return err
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Active", "Label", "User", "URL", "Type"})
-		table.SetAlignment(tablewriter.ALIGN_LEFT)
-		table.SetAutoWrapText(false)
-
 		// Sorts the keys of the map
 		var keys []string
 		for k := range list.Configs {
@@ -42,6 +47,21 @@ var configListCmd = &cobra.Command{
 		}
 		sort.Strings(keys)
 
+		// Secrets of the profiles that skip the keyring are never printed
+		var profiles []profileSummary
+		for _, id := range keys {
+			c := list.Configs[id]
+			profiles = append(profiles, profileSummary{ID: id, Active: id == list.ActiveConfigID, Label: c.Label, User: c.User, URL: c.Url, AuthType: c.AuthType})
+		}
+		if done, err := printStructured(os.Stdout, profiles); done {
+			return err
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Active", "Label", "User", "URL", "Type"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+
 		for _, val := range keys {
 			if val == list.ActiveConfigID {
 				table.Append([]string{"\u2713", list.Configs[val].Label, list.Configs[val].User, list.Configs[val].Url, list.Configs[val].AuthType})
@@ -57,12 +77,37 @@ var configListCmd = &cobra.Command{
 var configUseCmd = &cobra.Command{
 	Use:   "use",
 	Short: "Define as active, one of the current authentication profiles",
+	Long: `
+DESCRIPTION
+
+  Define the profile that is used by default. The profile is designated by its ID or its label,
+  if none is passed, you are prompted to select one among the stored profiles.
+
+  To use another profile for a single command without changing the active one, use the --profile flag instead.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` config use admin@files.example.com
+`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cl, err := rest.GetConfigList()
 		if err != nil {
 			return err
 		}
 
+		if len(args) == 1 {
+			id, err := cl.FindConfigID(args[0])
+			if err != nil {
+				return err
+			}
+			if err = setActiveConfig(id); err != nil {
+				return err
+			}
+			fmt.Printf("The active configuration is: %s\n", id)
+			return nil
+		}
+
 		// interactive mode with promptui
 		var items []string
 
@@ -79,6 +124,7 @@ var configUseCmd = &cobra.Command{
 			}
 		}
 
+		active := cl.ActiveConfigID
 		if len(items) > 0 {
 			pSelect := promptui.Select{Label: "Select the account you want to use", Items: items, Size: len(items), CursorPos: initialCursor}
 			_, result, err := pSelect.Run()
@@ -86,22 +132,25 @@ var configUseCmd = &cobra.Command{
 				return err
 			}
 
-			if err := cl.SetActiveConfig(result); err != nil {
+			if err := setActiveConfig(result); err != nil {
 				return err
 			}
+			active = result
 		}
 
-		if err := cl.SaveConfigFile(); err != nil {
-			return err
-		}
-
-		fmt.Printf("The active configuration is: %s\n", cl.ActiveConfigID)
+		fmt.Printf("The active configuration is: %s\n", active)
 		return nil
 	},
 }
 var configRemoveCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "Remove a profile from the cells-client authentication profiles",
+	Long: `
+DESCRIPTION
+
+  Remove a profile and its sensitive information from the keyring.
+  Tokens of OAuth2 profiles are first revoked on the server, unless the --local-only flag is set.
+`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		cl, err := rest.GetConfigList()
@@ -131,14 +180,6 @@ var configRemoveCmd = &cobra.Command{
 			}
 			items = append(items[:index], items[index+1:]...)
 
-			// TODO also remove the key from the keyring
-			if !cl.Configs[removed].SkipKeyring {
-				err = rest.ClearKeyring(cl.Configs[removed])
-				if err != nil {
-					return fmt.Errorf("could not clear keyring for %s: %s \n ==> Aborting... ", removed, err.Error())
-				}
-			}
-
 			if removed != cl.ActiveConfigID && len(items) > 1 {
 				pSelect2 := promptui.Select{Label: "Please select the new active configuration", Items: items, Size: len(items)}
 				_, active, err = pSelect2.Run()
@@ -153,6 +194,9 @@ var configRemoveCmd = &cobra.Command{
 			return fmt.Errorf("configuration list is empty")
 		}
 
+		if err = revokeProfile(cl.Configs[removed]); err != nil {
+			return fmt.Errorf("could not revoke tokens for %s: %s", removed, err.Error())
+		}
 		if !cl.Configs[removed].SkipKeyring {
 			err = rest.ClearKeyring(cl.Configs[removed])
 			if err != nil {
@@ -160,23 +204,22 @@ var configRemoveCmd = &cobra.Command{
 			}
 		}
 
-		if err := cl.Remove(removed); err != nil {
-			return err
-		}
-
-		if active != "" {
-			if err := cl.SetActiveConfig(active); err != nil {
+		err = rest.UpdateConfigList(func(list *rest.ConfigList) error {
+			if err := list.Remove(removed); err != nil {
 				return err
 			}
-		}
-
-		if err := cl.SaveConfigFile(); err != nil {
+			if active != "" {
+				return list.SetActiveConfig(active)
+			}
+			return nil
+		})
+		if err != nil {
 			return err
 		}
 
 		cmd.Printf("Removed the following configuration %s\n\n", removed)
 		if active != "" {
-			cmd.Printf("The new active configuration is: %s\n", cl.ActiveConfigID)
+			cmd.Printf("The new active configuration is: %s\n", active)
 		}
 
 		return nil
@@ -194,6 +237,7 @@ DESCRIPTION
 `,
 	Run: func(cm *cobra.Command, args []string) {
 
+		fmt.Println("Checking keyring service for", rest.KeyringServiceName())
 		if err := rest.CheckKeyring(); err != nil {
 			fmt.Println(promptui.IconWarn + " " + rest.NoKeyringMsg)
 			os.Exit(1)
@@ -208,5 +252,13 @@ func init() {
 	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configRemoveCmd)
 	configCmd.AddCommand(checkKeyringCmd)
+	configRemoveCmd.Flags().BoolVar(&localOnly, "local-only", false, "Do not revoke the tokens on the server, only remove local information")
 	RootCmd.AddCommand(configCmd)
 }
+
+// setActiveConfig changes the active profile in the config file.
+func setActiveConfig(id string) error {
+	return rest.UpdateConfigList(func(list *rest.ConfigList) error {
+		return list.SetActiveConfig(id)
+	})
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	exportWithSecrets bool
+	importOverwrite   bool
+)
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export profiles to share them or to move them to another machine",
+	Long: `
+DESCRIPTION
+
+  Print the profiles that are designated by their ID or their label, or all profiles if none is passed, in a format
+  that can be read by the 'config import' command.
+
+  Sensitive information (tokens and passwords) is left out by default: the person who imports the profiles then only
+  has to log in. With the --with-secrets flag, it is included in the export, encrypted with a passphrase that is read
+  from the ` + rest.SecretKeyEnv + ` environment variable or prompted.
+
+EXAMPLES
+
+  # Share the profiles of your team, without your credentials
+  ` + os.Args[0] + ` config export > team-profiles.json
+
+  # Move a profile to another machine
+  ` + os.Args[0] + ` config export admin@files.example.com --with-secrets > admin.json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cl, err := rest.GetConfigList()
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for _, arg := range args {
+			id, err := cl.FindConfigID(arg)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		data, err := rest.ExportProfiles(ids, exportWithSecrets)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import profiles that have been exported with 'config export'",
+	Long: `
+DESCRIPTION
+
+  Add the profiles of a file that has been produced by the 'config export' command to your profiles.
+  The file is read from the standard input if no path is passed.
+
+  Profiles that already exist are kept, unless the --force flag is set.
+  If the file contains encrypted secrets, their passphrase is read from the ` + rest.SecretKeyEnv + ` environment variable or prompted,
+  and they are stored in your keyring. Otherwise, use '` + os.Args[0] + ` configure' to log in with the imported profiles.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` config import team-profiles.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if len(args) == 1 {
+			data, err = ioutil.ReadFile(args[0])
+		} else {
+			data, err = ioutil.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return err
+		}
+		imported, skipped, err := rest.ImportProfiles(data, importOverwrite)
+		if err != nil {
+			return err
+		}
+		for _, id := range imported {
+			fmt.Printf("%s Imported %s\n", promptui.IconGood, id)
+		}
+		for _, id := range skipped {
+			fmt.Printf("%s Skipped %s, that already exists: use --force to replace it\n", promptui.IconWarn, id)
+		}
+		return nil
+	},
+}
+
+var configRenameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Change the label of a profile",
+	Long: `
+DESCRIPTION
+
+  Change the label of the profile that is designated by its ID or its current label.
+  The label can then be used to designate the profile, e.g. with the --profile flag.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` config rename admin@files.example.com prod
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateProfile(args[0], func(c *rest.CecConfig) error {
+			return c.SetProperty(rest.PropertyLabel, args[1])
+		})
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Modify properties of a profile",
+	Long: `
+DESCRIPTION
+
+  Modify one or more properties of the profile that is designated by its ID or its label.
+  Supported properties are:
+    - ` + rest.PropertyLabel + `: the label of the profile
+    - ` + rest.PropertySkipVerify + `: true to skip the verification of the TLS certificate of the server (not recommended)
+    - ` + rest.PropertyCAFile + `: path to a PEM bundle of certificate authorities to trust, in addition to the system ones
+    - ` + rest.PropertyClientCert + `, ` + rest.PropertyClientKey + `: paths to the PEM client certificate and key, for mutual TLS
+    - ` + rest.PropertyProxyURL + `: URL of the HTTP proxy to use, HTTPS_PROXY and HTTP_PROXY are used if empty
+    - ` + rest.PropertyHeaderPrefix + `<name>: a custom header that is sent with each request, an empty value removes it
+
+EXAMPLES
+
+  ` + os.Args[0] + ` config set prod skipVerify=false header.X-Tenant=acme
+  ` + os.Args[0] + ` config set prod header.X-Tenant=
+  ` + os.Args[0] + ` config set prod caFile=/etc/ssl/internal-ca.pem proxyUrl=http://proxy.example.com:3128
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateProfile(args[0], func(c *rest.CecConfig) error {
+			for _, arg := range args[1:] {
+				parts := strings.SplitN(arg, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("invalid property %s, expected key=value", arg)
+				}
+				if err := c.SetProperty(parts[0], parts[1]); err != nil {
+					return err
+				}
+			}
+			// Check the TLS and proxy settings now rather than at the next connection
+			_, err := rest.NewHTTPTransport(c)
+			return err
+		})
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration that is used to connect to the server",
+	Long: `
+DESCRIPTION
+
+  Print the configuration that is resolved from the flags, the environment variables and the active profile,
+  or of the profile that is designated by its ID or its label. Sensitive information is masked.
+  Nothing is sent to the server: the token is shown as it is stored, even if it has expired.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` config show
+  ` + os.Args[0] + ` config show prod
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			profile = args[0]
+		}
+		c, err := resolveConfig()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no configuration has been found, please make sure to run '%s configure' first", os.Args[0])
+			}
+			return err
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Property", "Value"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		table.Append([]string{"Label", c.Label})
+		table.Append([]string{"URL", c.Url})
+		table.Append([]string{"User", c.User})
+		table.Append([]string{"Auth type", c.AuthType})
+		table.Append([]string{"Skip verify", strconv.FormatBool(c.SkipVerify)})
+		table.Append([]string{"CA file", c.CAFile})
+		table.Append([]string{"Client certificate", c.ClientCertFile})
+		table.Append([]string{"Client key", c.ClientKeyFile})
+		table.Append([]string{"Proxy URL", c.ProxyURL})
+		table.Append([]string{"Skip keyring", strconv.FormatBool(c.SkipKeyring)})
+		table.Append([]string{"Secret backend", c.SecretBackend})
+		if c.SecretCommand != "" {
+			table.Append([]string{"Secret command", c.SecretCommand})
+		}
+		table.Append([]string{"Token", maskSecret(c.IdToken)})
+		table.Append([]string{"Refresh token", maskSecret(c.RefreshToken)})
+		table.Append([]string{"Password", maskSecret(c.Password)})
+		if c.TokenExpiresAt > 0 {
+			table.Append([]string{"Token expires at", time.Unix(int64(c.TokenExpiresAt), 0).Format(time.RFC3339)})
+		}
+		var names []string
+		for k := range c.CustomHeaders {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			table.Append([]string{"Header " + k, c.CustomHeaders[k]})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// updateProfile applies the modification to the profile that is designated by its ID or its label, and saves it.
+func updateProfile(idOrLabel string, modify func(c *rest.CecConfig) error) error {
+	var id string
+	err := rest.UpdateConfigList(func(list *rest.ConfigList) error {
+		var err error
+		if id, err = list.FindConfigID(idOrLabel); err != nil {
+			return err
+		}
+		c := list.Configs[id]
+		oldLabel := c.Label
+		if err = modify(c); err != nil {
+			return err
+		}
+		if c.Label == oldLabel {
+			return nil
+		}
+		for otherID, other := range list.Configs {
+			if otherID != id && other.Label == c.Label {
+				return fmt.Errorf("label %s is already used by %s", c.Label, otherID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Profile %s has been updated\n", promptui.IconGood, id)
+	return nil
+}
+
+// maskSecret hides all but the last characters of long secrets.
+func maskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) < 16 {
+		return "********"
+	}
+	return "********" + s[len(s)-4:]
+}
+
+func init() {
+	configExportCmd.Flags().BoolVar(&exportWithSecrets, "with-secrets", false, "Include tokens and passwords, encrypted with a passphrase")
+	configImportCmd.Flags().BoolVarP(&importOverwrite, "force", "f", false, "Replace the profiles that already exist")
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configCmd.AddCommand(configRenameCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configShowCmd)
+}
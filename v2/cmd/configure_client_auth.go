@@ -36,6 +36,7 @@ USAGE
 			SkipKeyring: skipKeyring,
 			AuthType:    common.ClientAuthType,
 		}
+		applyTransportFlags(newConf)
 
 		var err error
 		if notEmpty(serverURL) == nil && notEmpty(login) == nil && notEmpty(password) == nil {
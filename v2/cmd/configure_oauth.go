@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/gookit/color"
@@ -18,7 +19,10 @@ import (
 	"github.com/pydio/cells-client/v2/rest"
 )
 
-var oauthIDToken string
+var (
+	oauthIDToken string
+	oauthDevice  bool
+)
 
 // This cannot be changed on the client side: the callback URL, including this port, must be registered on the server side.
 const callbackPort = 3000
@@ -47,7 +51,12 @@ USAGE
 
   This command launches an interactive process that gather necessary information.
   If you are on a workstation with a browser, you are then redirected to your Cells' web UI to authenticate.
-  Otherwise, we provide you with a link that will help you terminate the procedure with 2 copy/pastes.
+  Otherwise (typically on a server that you access via SSH), we provide you with a link and a short code:
+  open the link on any device with a browser, enter the code and log in, the procedure then terminates automatically.
+  If the server does not support this device authorization flow, the link will help you terminate the procedure with 2 copy/pastes.
+
+  The device authorization flow can also be started directly with the --device flag, e.g:
+    ` + os.Args[0] + ` configure oauth --url https://files.example.com --device
   
   If you are quick enough, (or if the default JWT token duration is long enough), 
   you can also initialise this configuration by providing an ID token that you have retrieved using an alternative procedure,
@@ -59,10 +68,15 @@ USAGE
 			SkipKeyring: skipKeyring,
 			AuthType:    common.OAuthType,
 		}
+		applyTransportFlags(newConf)
 
 		var err error
 		if serverURL != "" && oauthIDToken != "" {
 			err = oAuthNonInteractive(newConf)
+		} else if serverURL != "" && oauthDevice {
+			newConf.Url = serverURL
+			newConf.SkipVerify = skipVerify
+			err = oAuthDeviceFlow(newConf)
 		} else {
 			err = oAuthInteractive(newConf)
 		}
@@ -136,7 +150,7 @@ func oAuthInteractive(newConf *rest.CecConfig) error {
 	}
 
 	openBrowser := true
-	p3 := promptui.Select{Label: "Can you open a browser on this computer? If not, you will make the authentication process by copy/pasting", Items: []string{"Yes", "No"}}
+	p3 := promptui.Select{Label: "Can you open a browser on this computer? If not, you will authenticate with a browser on another device", Items: []string{"Yes", "No"}}
 	if _, v, e := p3.Run(); e == nil && v == "No" {
 		openBrowser = false
 	}
@@ -152,10 +166,22 @@ func oAuthInteractive(newConf *rest.CecConfig) error {
 		openBrowser = false
 	}
 
+	if !openBrowser {
+		// Prefer the device authorization flow, that does not require any copy/paste
+		if e = oAuthDeviceFlow(newConf); e == nil {
+			return nil
+		}
+		fmt.Printf("%s Device authorization flow failed (%s), falling back to copy/paste\n", promptui.IconWarn, e.Error())
+	}
+
 	// Starting authentication process
 	var returnCode string
 	state := rest.RandString(16)
-	directUrl, callbackUrl, err := rest.OAuthPrepareUrl(newConf.Url, state, openBrowser)
+	verifier, err := rest.NewPKCEVerifier()
+	if err != nil {
+		log.Fatal(err)
+	}
+	directUrl, callbackUrl, err := rest.OAuthPrepareUrl(newConf.Url, state, verifier, openBrowser)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -192,7 +218,7 @@ func oAuthInteractive(newConf *rest.CecConfig) error {
 	}
 
 	fmt.Println(promptui.IconGood + " Now exchanging the code for a valid IdToken")
-	if err := rest.OAuthExchangeCode(&newConf.SdkConfig, returnCode, callbackUrl); err != nil {
+	if err := rest.OAuthExchangeCode(newConf, returnCode, callbackUrl, verifier); err != nil {
 		log.Fatal(err)
 	}
 	fmt.Printf("%s Successfully Received Token. It will be refreshed at %v\n", promptui.IconGood, time.Unix(int64(newConf.TokenExpiresAt), 0))
@@ -200,6 +226,29 @@ func oAuthInteractive(newConf *rest.CecConfig) error {
 	return nil
 }
 
+// oAuthDeviceFlow retrieves tokens with the device authorization flow: the user approves the access with a browser on another device.
+func oAuthDeviceFlow(newConf *rest.CecConfig) error {
+	da, err := rest.OAuthStartDeviceFlow(newConf)
+	if err != nil {
+		return err
+	}
+	col := color.FgLightRed.Render
+	if da.VerificationURIComplete != "" {
+		fmt.Println("Please open this URL in a browser on any device and log in:", col(da.VerificationURIComplete))
+		fmt.Println("Make sure that the following code is displayed:", col(da.UserCode))
+	} else {
+		fmt.Println("Please open this URL in a browser on any device:", col(da.VerificationURI))
+		fmt.Println("And enter the following code:", col(da.UserCode))
+	}
+	fmt.Println("Waiting for the authorization...")
+	if err = rest.OAuthPollDeviceToken(newConf, da); err != nil {
+		// Let the caller fall back to another flow, e.g. when the user has denied the request or the code has expired
+		return err
+	}
+	fmt.Printf("%s Successfully Received Token. It will be refreshed at %v\n", promptui.IconGood, time.Unix(int64(newConf.TokenExpiresAt), 0))
+	return nil
+}
+
 func isPortAvailable(port int, timeout int) bool {
 	conn, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -238,6 +287,7 @@ func oAuthNonInteractive(conf *rest.CecConfig) error {
 func init() {
 	flags := configureOAuthCmd.PersistentFlags()
 	flags.StringVar(&oauthIDToken, "id_token", "", "A currently valid OAuth2 ID token, retrived via the OIDC credential flow")
+	flags.BoolVar(&oauthDevice, "device", false, "Authenticate with a browser on another device, using the device authorization flow")
 	configureCmd.AddCommand(configureOAuthCmd)
 	configAddCmd.AddCommand(configureOAuthCmd)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/manifoldco/promptui"
@@ -14,6 +15,8 @@ import (
 	"github.com/pydio/cells-client/v2/rest"
 )
 
+var tokenGenerate bool
+
 var configurePersonalAccessTokenCmd = &cobra.Command{
 	Use:   "token",
 	Short: "Configure Authentication using a Personal Access Token",
@@ -21,8 +24,14 @@ var configurePersonalAccessTokenCmd = &cobra.Command{
 DESCRIPTION
 
   Configure your Cells Client to connect to your distant server using a Personal Acces Token.
-  A token can be generated with the 'token' command for a given user on the server side (not in this client),
-  see 'cells admin user token --help' for further details.
+  A token can be generated with the '` + os.Args[0] + ` token create' command, or on the server side with
+  the 'cells admin user token' command, see 'cells admin user token --help' for further details.
+
+  With the --generate flag, a dedicated token is generated for the user of the current profile, that must be
+  logged in with OAuth2: as there is one profile per user and server, this profile then uses the token instead
+  of the OAuth2 session, e.g. for a machine that runs unattended. The --label, --expires and --scope flags
+  describe the token to generate, e.g:
+    ` + os.Args[0] + ` config add token --generate --label backup-server --expires 90d
 
   Please beware that the Personal Access Token will be stored in clear text if you do not have a **correctly configured and running** keyring on your client machine.
 
@@ -30,10 +39,18 @@ DESCRIPTION
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		if tokenGenerate {
+			if err := persistConfig(generateTokenConfig()); err != nil {
+				log.Fatalf(err.Error())
+			}
+			return
+		}
+
 		newConf := &rest.CecConfig{
 			AuthType:    common.PatType,
 			SkipKeyring: skipKeyring,
 		}
+		applyTransportFlags(newConf)
 
 		var err error
 		if token != "" && serverURL != "" {
@@ -92,7 +109,44 @@ DESCRIPTION
 	},
 }
 
+// generateTokenConfig generates a personal access token with the current OAuth2 profile,
+// and returns a new config that uses it to connect to the same server.
+func generateTokenConfig() *rest.CecConfig {
+	expiresAt, err := parseTokenExpiry(tokenExpires)
+	if err != nil {
+		log.Fatal(err)
+	}
+	requireEnvironment()
+	current := rest.DefaultConfig
+	if current.AuthType != common.OAuthType {
+		log.Fatalf("A token can only be generated with a profile that is logged in with OAuth2, %s uses %s", current.Url, current.AuthType)
+	}
+	label := tokenLabel
+	if label == "" {
+		host, _ := os.Hostname()
+		label = fmt.Sprintf("%s on %s", common.AppName, host)
+	}
+	value, err := rest.GeneratePersonalToken(label, expiresAt, tokenScopes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	newConf := &rest.CecConfig{
+		TransportSettings: current.TransportSettings,
+		AuthType:          common.PatType,
+		SkipKeyring:       skipKeyring,
+	}
+	newConf.Url = current.Url
+	newConf.SkipVerify = current.SkipVerify
+	newConf.IdToken = value
+	applyTransportFlags(newConf)
+	return newConf
+}
+
 func init() {
+	flags := configurePersonalAccessTokenCmd.Flags()
+	flags.BoolVar(&tokenGenerate, "generate", false, "Generate a dedicated token with the current OAuth2 profile instead of passing an existing one")
+	addTokenFlags(flags)
 	configureCmd.AddCommand(configurePersonalAccessTokenCmd)
 	configAddCmd.AddCommand(configurePersonalAccessTokenCmd)
 }
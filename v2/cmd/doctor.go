@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var doctorFolder string
+
+// doctorStep is a single check of the doctor command. If a critical step fails, the following steps are skipped.
+type doctorStep struct {
+	name     string
+	critical bool
+	run      func() (string, error)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the connection to the server and the local configuration",
+	Long: `
+DESCRIPTION
+
+  Check, step by step, everything that is required to work with your server, and show how long each step took:
+   - the resolution of the server name,
+   - the validity of the TLS certificate chain of the server,
+   - that the boot configuration of the server can be retrieved,
+   - that the keyring (or the secret backend of the profile) works and holds the credentials of the profile,
+   - the validity of the authentication token, that is refreshed if required,
+   - that the version of the server is supported by this client,
+   - that the S3 gateway, that is used to transfer files, answers,
+   - if a folder is passed with --folder, that a small file can be written, read and deleted in it.
+
+  When a step fails, the steps that depend on it are skipped. The command exits with status 1 if a step failed.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` doctor
+  ` + os.Args[0] + ` doctor --profile prod --folder personal-files
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		var c rest.CecConfig
+		var stored, secretsLoaded bool
+		steps := []doctorStep{
+			{name: "Configuration", critical: true, run: func() (string, error) {
+				// Secrets are only loaded in the keyring step, so that a broken keyring does not hide the other checks
+				var err error
+				if c, stored, err = resolveConfigWithoutSecrets(); err != nil {
+					if os.IsNotExist(err) {
+						return "", fmt.Errorf("no configuration has been found, please run '%s configure' first", os.Args[0])
+					}
+					return "", err
+				}
+				secretsLoaded = !stored || c.SkipKeyring
+				rest.DefaultConfig = &c
+				return fmt.Sprintf("%s on %s (%s)", c.Label, c.Url, c.AuthType), nil
+			}},
+			{name: "DNS resolution", critical: true, run: func() (string, error) { return rest.CheckDNS(&c) }},
+			{name: "TLS certificate", critical: true, run: func() (string, error) { return rest.CheckTLS(&c) }},
+			{name: "Boot configuration", critical: true, run: func() (string, error) { return rest.CheckBootconf(&c) }},
+			{name: "Keyring", run: func() (string, error) {
+				if !stored {
+					return "", &rest.CheckWarning{Message: "not used, the connection is defined by flags or environment variables"}
+				}
+				if c.SkipKeyring {
+					return "", &rest.CheckWarning{Message: "not used, sensitive information is stored in clear text"}
+				}
+				if rest.SecretBackend == "" {
+					rest.SecretBackend = c.SecretBackend
+					if c.SecretCommand != "" {
+						rest.SecretCommand = c.SecretCommand
+					}
+				}
+				if err := rest.CheckKeyring(); err != nil {
+					return "", err
+				}
+				if err := rest.ConfigFromKeyring(&c); err != nil {
+					return "", fmt.Errorf("could not load the credentials of the profile: %s", err.Error())
+				}
+				secretsLoaded = true
+				return "working with service " + rest.KeyringServiceName(), nil
+			}},
+			{name: "Authentication", critical: true, run: func() (string, error) {
+				if !secretsLoaded {
+					return "", fmt.Errorf("the credentials of the profile are not available")
+				}
+				if _, err := rest.RefreshAndStoreIfRequired(&c); err != nil {
+					return "", err
+				}
+				login, err := rest.RetrieveCurrentSessionLogin()
+				if err != nil {
+					return "", err
+				}
+				detail := "authenticated as " + login
+				if st := rest.GetTokenStatus(&c); !st.ExpiresAt.IsZero() {
+					detail += ", token valid for " + st.Remaining().Round(time.Second).String()
+				}
+				return detail, nil
+			}},
+			{name: "Server version", run: rest.CheckServerVersion},
+			{name: "S3 gateway", run: rest.CheckS3Gateway},
+			{name: "Write, read, delete", run: func() (string, error) {
+				if doctorFolder == "" {
+					return "skipped, use --folder to run this check", nil
+				}
+				return rest.CheckReadWrite(doctorFolder)
+			}},
+		}
+
+		failed := false
+		skip := false
+		for _, step := range steps {
+			if skip {
+				fmt.Printf("%s %-20s %8s  skipped\n", promptui.IconInitial, step.name, "")
+				continue
+			}
+			start := time.Now()
+			detail, err := step.run()
+			took := time.Since(start).Round(time.Millisecond).String()
+			var warning *rest.CheckWarning
+			switch {
+			case err == nil:
+				fmt.Printf("%s %-20s %8s  %s\n", promptui.IconGood, step.name, took, detail)
+			case errors.As(err, &warning):
+				fmt.Printf("%s %-20s %8s  %s\n", promptui.IconWarn, step.name, took, warning.Message)
+			default:
+				fmt.Printf("%s %-20s %8s  %s\n", promptui.IconBad, step.name, took, err.Error())
+				failed = true
+				skip = step.critical
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorFolder, "folder", "", "Remote folder where a small file is written, read and deleted, e.g: personal-files")
+	RootCmd.AddCommand(doctorCmd)
+}
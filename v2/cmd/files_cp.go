@@ -1,13 +1,10 @@
 package cmd
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"path"
-	"strings"
 
-	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 
 	"github.com/pydio/cells-sdk-go/v3/models"
@@ -26,12 +23,14 @@ DESCRIPTION
 
 WILD-CHARS
 
-  In version 2, we only support the '*' wild char as a standalone token of the source path, that is:
-    - '` + os.Args[0] + ` cp common-files/test/* common-files/target' is legit and will copy 
-	  all files and folder found in test folder on your server to the target folder
-	- '` + os.Args[0] + ` cp common-files/test/*.jpg ...' or '` + os.Args[0] + ` cp common-files/test/ima* ...'  
-	  will *not* work as some might expect: the system looks for a single file named respectively '*.jpg' or 'ima*'
-	  and will probably fail to find it (unless if a file with this name exists on your server...)
+  The source path can contain wild chars, they are expanded on the server side before launching the copy:
+    - '*' matches any sequence of characters in a file or folder name, e.g: common-files/test/*.jpg
+    - '?' matches any single character, e.g: common-files/test/img-??.png
+    - '[abc]' or '[a-z]' matches one of the characters in the class, '[!abc]' any character that is not in it
+    - '{a,b}' matches one of the comma-separated alternatives, e.g: common-files/test/*.{jpg,png}
+    - '**' used as a full segment matches any number of sub-folders, e.g: common-files/test/**/*.pdf
+
+  Do not forget to quote the path so that wild chars are not expanded by your local shell.
 
 EXAMPLE
 
@@ -42,7 +41,10 @@ EXAMPLE
   ` + os.Args[0] + ` cp common-files/test.txt personal-files/folder-b
 
   # Copy the full content of a folder inside another
-  ` + os.Args[0] + ` cp common-files/test/* common-files/folder-c
+  ` + os.Args[0] + ` cp 'common-files/test/*' common-files/folder-c
+
+  # Copy all JPG and PNG images found under a folder and its sub-folders
+  ` + os.Args[0] + ` cp 'common-files/test/**/*.{jpg,png}' common-files/images
 `,
 	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -52,15 +54,15 @@ EXAMPLE
 
 		// Pre-process source path
 		var sourceNodes []string
-		if path.Base(fromPath) == "*" {
-			nodes, err := rest.ListNodesPath(fromPath)
+		if rest.HasGlob(fromPath) {
+			nodes, err := rest.ExpandGlobPaths(fromPath)
 			if err != nil {
-				log.Fatalf("Preparing grouped copy, could not list all nodes under %s, cause: %s", path.Dir(fromPath), err.Error())
+				log.Fatalf("Preparing grouped copy, could not expand %s, cause: %s", fromPath, err.Error())
+			}
+			if len(nodes) == 0 {
+				log.Fatalf("No node found on the server matching %s", fromPath)
 			}
 			sourceNodes = nodes
-		} else if strings.HasSuffix(path.Base(fromPath), "*") {
-			fmt.Println(promptui.IconWarn + " We currently only support the '*' wild char without prefix, see help for further details")
-			sourceNodes = []string{fromPath}
 		} else {
 			sourceNodes = []string{fromPath}
 		}
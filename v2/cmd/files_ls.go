@@ -64,7 +64,15 @@ Huge Photo.jpg
 IMG_9723.JPG
 (...)
 
-4/ Check path existence.
+4/ Only listing nodes that match a pattern.
+
+$ ` + os.Args[0] + ` ls 'personal-files/*.{jpg,JPG}' -r
+personal-files/Huge Photo-1.jpg
+personal-files/Huge Photo.jpg
+personal-files/IMG_9723.JPG
+personal-files/P5021040.jpg
+
+5/ Check path existence.
 
 $ ` + os.Args[0] + ` ls personal-files/P5021040.jpg -f
 true
@@ -105,6 +113,9 @@ SYNTAX
 
   Note that you can only use *one* of the three above flags at a time.
 
+  The path can also contain wild chars, in such case all matching nodes are listed with their full path.
+  Supported wild chars are: '*', '?', '[abc]', '{a,b}' and '**', see the 'cp' command help for details.
+
 EXAMPLES
 
 ` + lsCmdExample + `
@@ -121,6 +132,11 @@ EXAMPLES
 		}
 		p := strings.Trim(lsPath, "/")
 
+		if rest.HasGlob(p) {
+			listMatchingNodes(cmd, p, dt)
+			return
+		}
+
 		// Connect to the Cells API
 		ctx, apiClient, err := rest.GetApiClient()
 		if err != nil {
@@ -164,6 +180,17 @@ EXAMPLES
 			return
 		}
 
+		if dt != raw {
+			// The listed folder is only followed by its children
+			nodes := result.Payload.Nodes
+			if first := nodes[0]; first.Path == "" || (first.Type != nil && *first.Type == models.TreeNodeTypeCOLLECTION) {
+				nodes = nodes[1:]
+			}
+			if printList(nodes) {
+				return
+			}
+		}
+
 		// Not very elegant way to check if we are at the workspace level
 		var wsLevel bool
 		if len(result.Payload.Nodes) > 1 {
@@ -265,6 +292,58 @@ EXAMPLES
 	},
 }
 
+// listMatchingNodes expands the passed pattern and displays all found nodes, using the requested display type.
+func listMatchingNodes(cmd *cobra.Command, pattern, dt string) {
+	nodes, err := rest.ExpandGlob(pattern)
+	if err != nil {
+		cmd.Printf("Could not list nodes matching %s, cause: %s\n", pattern, err.Error())
+		os.Exit(1)
+	}
+
+	if dt == exists {
+		cmd.Println(len(nodes) > 0)
+		return
+	}
+	if dt != raw && printList(nodes) {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, node := range nodes {
+		t := "File"
+		if node.Type != nil && *node.Type == models.TreeNodeTypeCOLLECTION {
+			t = "Folder"
+		}
+		currPath := strings.Trim(node.Path, "/")
+		switch dt {
+		case details:
+			table.Append([]string{t, node.UUID, currPath, sizeToBytes(node.Size), stampToDate(node.MTime)})
+		case raw:
+			if t == "Folder" {
+				currPath += "/"
+			}
+			_, _ = fmt.Fprintln(os.Stdout, currPath)
+		default:
+			table.Append([]string{t, currPath})
+		}
+	}
+
+	legend := fmt.Sprintf("Listing: %d results matching %s", len(nodes), pattern)
+	switch dt {
+	case details:
+		fmt.Println(legend)
+		table.SetHeader([]string{"Type", "Uuid", "Name", "Size", "Modified"})
+		table.Render()
+	case raw:
+		break
+	default:
+		fmt.Println(legend)
+		fmt.Println("Get more info by adding the -d (details) flag")
+		table.SetHeader([]string{"Type", "Name"})
+		table.Render()
+	}
+}
+
 func sanityCheck() string {
 	// Check that we do not have multiple flags
 	displayType := defaultList
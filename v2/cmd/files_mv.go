@@ -3,7 +3,6 @@ package cmd
 import (
 	"log"
 	"os"
-	"path"
 
 	"github.com/spf13/cobra"
 
@@ -30,7 +29,15 @@ EXAMPLES
   ` + os.Args[0] + ` mv common-files/picture.jpg common-files/p2.jpg
 
   Move all nodes recursively:
-  ` + os.Args[0] + ` mv common-files/photos/* personal-files/photos/
+  ` + os.Args[0] + ` mv 'common-files/photos/*' personal-files/photos/
+
+  Move all PDF files found in a folder and its sub-folders:
+  ` + os.Args[0] + ` mv 'common-files/docs/**/*.pdf' personal-files/archives/
+
+WILD-CHARS
+
+  The source path supports the same wild chars as the 'cp' command: '*', '?', '[abc]', '{a,b}' and '**'.
+  Do not forget to quote the path so that wild chars are not expanded by your local shell.
 `,
 	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -39,10 +46,13 @@ EXAMPLES
 		target := args[1]
 
 		var sourceNodes []string
-		if path.Base(source) == "*" {
-			nodes, err := rest.ListNodesPath(source)
+		if rest.HasGlob(source) {
+			nodes, err := rest.ExpandGlobPaths(source)
 			if err != nil {
-				log.Println("could not list the nodes path", err)
+				log.Fatalf("Could not expand %s, cause: %s\n", source, err.Error())
+			}
+			if len(nodes) == 0 {
+				log.Fatalf("No node found on the server matching %s\n", source)
 			}
 			sourceNodes = nodes
 		} else {
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/common"
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var presignExpires string
+
+var presignCmd = &cobra.Command{
+	Use:   "presign",
+	Short: "Create time-limited signed URLs to download or upload a file",
+	Long: `
+DESCRIPTION
+
+  Print a signed URL that gives access to a single file for a limited time, without creating a public link.
+  Such URLs can be handed to other tools (a CI job, curl, a customer...) that do not know your credentials.
+  Only the URL is printed on the standard output, warnings are printed on the standard error.
+
+WARNING
+
+  The URL is signed with the token of your current session and carries it:
+   - anyone holding the URL can use it until it expires, so only hand it to trusted parties,
+   - the URL stops working as soon as the token expires, even if the requested validity is longer,
+   - revoking the token (e.g. with 'logout') also invalidates all URLs that have been signed with it.
+  With OAuth2 authentication, tokens are short-lived: prefer a personal access token to create URLs that last longer.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var presignGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Create a signed URL to download a file",
+	Long: `
+DESCRIPTION
+
+  Print a signed URL that can be used to download the file at the passed path with a simple HTTP GET request.
+
+EXAMPLES
+
+  # Create a URL that is valid for 1 hour and use it
+  url=$(` + os.Args[0] + ` presign get common-files/build/artifact.zip --expires 1h)
+  curl -o artifact.zip "$url"
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		p := strings.Trim(args[0], "/")
+		node, exists := rest.StatNode(p)
+		if !exists {
+			log.Fatalf("No file found at %s", p)
+		}
+		if node.Type != nil && *node.Type == models.TreeNodeTypeCOLLECTION {
+			log.Fatalf("%s is a folder, only files can be downloaded with a signed URL", p)
+		}
+		printPresigned(rest.PresignGet(p, presignDuration()))
+	},
+}
+
+var presignPutCmd = &cobra.Command{
+	Use:   "put",
+	Short: "Create a signed URL to upload a file",
+	Long: `
+DESCRIPTION
+
+  Print a signed URL that can be used to upload a file at the passed path with a simple HTTP PUT request.
+  The parent folder must exist. If a file already exists at this path, it is overwritten by the upload.
+
+EXAMPLES
+
+  # Let a CI job upload its report
+  url=$(` + os.Args[0] + ` presign put common-files/reports/build-42.html --expires 30m)
+  curl -X PUT --upload-file report.html "$url"
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		p := strings.Trim(args[0], "/")
+		if parent := path.Dir(p); parent != "." {
+			if _, exists := rest.StatNode(parent); !exists {
+				log.Fatalf("Parent folder %s does not exist", parent)
+			}
+		}
+		printPresigned(rest.PresignPut(p, presignDuration()))
+	},
+}
+
+func presignDuration() time.Duration {
+	d, err := parseAge(presignExpires)
+	if err != nil {
+		log.Fatalf("Invalid value for --expires: %s", err.Error())
+	}
+	return d
+}
+
+func printPresigned(u string, err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+	requested := time.Now().Add(presignDuration())
+	if expiry, ok := rest.CredentialsExpiry(); ok && expiry.Before(requested) {
+		fmt.Fprintf(os.Stderr, "Warning: your current token expires at %s, the URL will stop working at this time.\n", expiry.Format("2006-01-02 15:04:05"))
+	} else if !ok && rest.DefaultConfig.AuthType != common.PatType {
+		fmt.Fprintln(os.Stderr, "Warning: the URL is only valid as long as the token of your current session.")
+	}
+	fmt.Fprintln(os.Stderr, "Warning: the URL carries your credentials, only share it with trusted parties.")
+	fmt.Println(u)
+}
+
+func init() {
+	presignCmd.PersistentFlags().StringVarP(&presignExpires, "expires", "e", "1h", "Validity of the URL, e.g: 30m, 12h or 7d (7 days at most)")
+
+	presignCmd.AddCommand(presignGetCmd)
+	presignCmd.AddCommand(presignPutCmd)
+	RootCmd.AddCommand(presignCmd)
+}
@@ -6,32 +6,44 @@ import (
 	"os"
 	"path"
 	"strings"
-	"sync"
 
 	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
+	"github.com/pydio/cells-sdk-go/v3/models"
+
 	"github.com/pydio/cells-client/v2/rest"
 )
 
-
 var (
-	force        bool
-	wildcardChar = "%"
+	force       bool
+	rmDryRun    bool
+	rmPermanent bool
+	rmRecursive bool
 )
 
+const legacyWildcardChar = "%"
+
 var rmCmd = &cobra.Command{
-	Use:     "rm",
-	Short:   "Trash files or folders",
-	Long:    `
+	Use:   "rm",
+	Short: "Trash files or folders",
+	Long: `
 DESCRIPTION
 	
   Delete specified files or folders. 
 	
-  In fact, it only moves specified files or folders to the recycle bin 
+  By default, it only moves specified files or folders to the recycle bin 
   that is at the root of the corresponding workspace, the trashed objects 
-  can be restored (from the web UI, this feature is not yet implemented 
-  in the Cells Client) 
+  can then be listed and restored with the 'trash' command.
+  Use the --permanent flag to bypass the recycle bin: removed objects are then lost forever.
+
+  As with the POSIX rm command, folders are only removed when the --recursive flag is set.
+  Before deleting anything, all targets are checked: if one of them is not found
+  or is a folder while the --recursive flag is not set, nothing is removed and the command fails.
+  With the --force flag, targets that are not found are simply ignored.
+
+  Use the --dry-run flag to only list the objects that would be removed, with their size.
 
 EXAMPLES
 
@@ -41,85 +53,172 @@ EXAMPLES
   # Remove a single file:
   ` + os.Args[0] + ` rm common-files/target.txt
 
-  # Remove recursively inside a folder, the wildcard is '%':
-  ` + os.Args[0] + ` rm common-files/folder/%
+  # Remove recursively inside a folder:
+  ` + os.Args[0] + ` rm -r 'common-files/folder/*'
+
+  # Remove all text files of a folder and its sub-folders:
+  ` + os.Args[0] + ` rm 'common-files/folder/**/*.txt'
 
   # Remove a folder and all its children (even if it is not empty)
-  ` + os.Args[0] + ` rm common-files/folder
+  ` + os.Args[0] + ` rm -r common-files/folder
 
   # Remove multiple files
   ` + os.Args[0] + ` rm common-files/file-1.txt common-files/file-2.txt
 
+  # Check what would be removed, without deleting anything
+  ` + os.Args[0] + ` rm -r --dry-run 'common-files/folder/*'
+
+  # Permanently delete a file, without moving it to the recycle bin
+  ` + os.Args[0] + ` rm --permanent common-files/file-1.txt
+
   # You can force the deletion with the '--force' flag (to avoid the Yes or No)
   ` + os.Args[0] + ` rm -f common-files/file-1.txt
+
+WILD-CHARS
+
+  Paths support the same wild chars as the 'cp' command: '*', '?', '[abc]', '{a,b}' and '**'.
+  Do not forget to quote the paths so that wild chars are not expanded by your local shell.
+  The recycle bin of the workspaces is never matched by wild chars.
+
+  The legacy '%' wild char is still supported as the last segment of a path and is equivalent to '*'.
 `,
-	Args:    cobra.MinimumNArgs(1),
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
+		targetNodes, errs := resolveRmTargets(args)
+		if len(errs) > 0 {
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "%s %s\n", promptui.IconBad, e.Error())
+			}
+			fmt.Fprintln(os.Stderr, "Nothing has been deleted")
+			os.Exit(1)
+		}
+
+		if len(targetNodes) <= 0 {
+			fmt.Println("Nothing to delete")
+			return
+		}
+
+		if rmDryRun || !force {
+			listRmTargets(targetNodes)
+		}
+		if rmDryRun {
+			return
+		}
+
 		// Ask for user approval before deleting
-		p := promptui.Select{Label: "Are you sure", Items: []string{"No", "Yes"}}
 		if !force {
-			if _, resp, e := p.Run(); resp == "No" && e == nil {
-				log.Println("Nothing will be deleted")
+			label := "Are you sure"
+			if rmPermanent {
+				label = "These objects will be permanently deleted, are you sure"
+			}
+			p := promptui.Select{Label: label, Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
 				return
 			}
 		}
 
-		targetNodes := make([]string, 0)
-		for _, arg := range args {
-			_, exists := rest.StatNode(strings.TrimRight(arg, wildcardChar))
-			if !exists {
-				log.Printf("Node not found %v, could not delete\n", arg)
-			}
-			if path.Base(arg) == wildcardChar {
-				dir, _ := path.Split(arg)
-				newArg := path.Join(dir, "*")
-				nodes, err := rest.ListNodesPath(newArg)
-
-				// Remove recycle_bin from targetedNodes
-				for i, c := range nodes {
-					if path.Base(c) == "recycle_bin" {
-						nodes = append(nodes[:i], nodes[i+1:]...)
-					}
-				}
+		var paths []string
+		for _, n := range targetNodes {
+			paths = append(paths, strings.Trim(n.Path, "/"))
+		}
+		jobUUID, err := rest.DeleteNode(paths, rmPermanent)
+		if err != nil {
+			log.Fatalf("could not delete nodes, cause: %s\n", err)
+		}
+		if !monitorJobs(jobUUID) {
+			os.Exit(1)
+		}
 
-				if err != nil {
-					log.Fatalf("Could not list nodes inside %s, aborting. Cause: %s\n", path.Dir(arg), err.Error())
-				}
-				targetNodes = append(targetNodes, nodes...)
-			} else {
-				targetNodes = append(targetNodes, arg)
-			}
+		if rmPermanent {
+			fmt.Println("Nodes have been permanently deleted")
+		} else {
+			fmt.Println("Nodes have been moved to the Recycle Bin")
 		}
+	},
+}
 
-		if len(targetNodes) <= 0 {
-			log.Println("Nothing to delete")
+// resolveRmTargets expands and checks the passed paths, returning the nodes to delete
+// and the list of problems that prevent the deletion.
+func resolveRmTargets(args []string) ([]*models.TreeNode, []error) {
+	var targets []*models.TreeNode
+	var errs []error
+	seen := make(map[string]bool)
+	add := func(n *models.TreeNode) {
+		p := strings.Trim(n.Path, "/")
+		if seen[p] {
 			return
 		}
-
-		jobUUID, err := rest.DeleteNode(targetNodes)
-		if err != nil {
-			log.Fatalf("could not delete nodes, cause: %s\n", err)
+		seen[p] = true
+		if n.Type != nil && *n.Type == models.TreeNodeTypeCOLLECTION && !rmRecursive {
+			errs = append(errs, fmt.Errorf("cannot remove %s: is a folder, use the --recursive flag", p))
+			return
 		}
+		targets = append(targets, n)
+	}
 
-		var wg sync.WaitGroup
-		for _, id := range jobUUID {
-			wg.Add(1)
-			go func(id string) {
-				err := rest.MonitorJob(id)
-				defer wg.Done()
-				if err != nil {
-					log.Printf("could not monitor job, %s\n", id)
+	for _, arg := range args {
+		arg = strings.Trim(arg, "/")
+		if path.Base(arg) == legacyWildcardChar {
+			arg = path.Join(path.Dir(arg), "*")
+		}
+		if !rest.HasGlob(arg) {
+			n, exists, err := rest.LookupNode(arg)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cannot remove %s: %s", arg, err.Error()))
+				continue
+			}
+			if !exists {
+				if !force {
+					errs = append(errs, fmt.Errorf("cannot remove %s: no such file or folder", arg))
 				}
-			}(id)
+				continue
+			}
+			add(n)
+			continue
 		}
-		wg.Wait()
 
-		fmt.Println("Nodes have been moved to the Recycle Bin")
-	},
+		nodes, err := rest.ExpandGlob(arg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not expand %s: %s", arg, err.Error()))
+			continue
+		}
+		// Folders are removed with their content: do not try to remove it twice
+		for _, n := range rest.RemoveNestedNodes(nodes) {
+			add(n)
+		}
+		if len(nodes) == 0 && !force {
+			errs = append(errs, fmt.Errorf("cannot remove %s: no matching file or folder", arg))
+		}
+	}
+	return targets, errs
+}
+
+func listRmTargets(nodes []*models.TreeNode) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Type", "Path", "Size"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	for _, n := range nodes {
+		t := "File"
+		if n.Type != nil && *n.Type == models.TreeNodeTypeCOLLECTION {
+			t = "Folder"
+		}
+		table.Append([]string{t, strings.Trim(n.Path, "/"), sizeToBytes(n.Size)})
+	}
+	if rmPermanent {
+		fmt.Printf("%d objects will be permanently deleted:\n", len(nodes))
+	} else {
+		fmt.Printf("%d objects will be moved to the recycle bin:\n", len(nodes))
+	}
+	table.Render()
 }
 
 func init() {
 	RootCmd.AddCommand(rmCmd)
-	rmCmd.Flags().BoolVarP(&force, "force", "f", false, "Do not ask for user approval")
+	rmCmd.Flags().BoolVarP(&force, "force", "f", false, "Do not ask for user approval and ignore targets that are not found")
+	rmCmd.Flags().BoolVar(&rmDryRun, "dry-run", false, "Only list the objects that would be removed, without deleting anything")
+	rmCmd.Flags().BoolVar(&rmPermanent, "permanent", false, "Permanently delete the objects instead of moving them to the recycle bin")
+	rmCmd.Flags().BoolVarP(&rmRecursive, "recursive", "r", false, "Remove folders and their content")
 }
@@ -56,6 +56,17 @@ EXAMPLES
   3/ Download a file changing its name - remember: this will fail if a 'cat2.jpg' file already exists: 
   $ ` + os.Args[0] + ` scp cells://personal-files/funnyCat.jpg ./cat2.jpg
   Copying cells://personal-files/funnyCat.jpg to /home/pydio/downloads/	
+
+  4/ Download all pictures found in a remote folder and its sub-folders to an existing local folder:
+  $ ` + os.Args[0] + ` scp 'cells://personal-files/**/*.{jpg,png}' ./pictures/
+
+WILD-CHARS
+
+  When downloading, the remote source path supports the same wild chars as the 'cp' command: 
+  '*', '?', '[abc]', '{a,b}' and '**'. In such case, the target must be an existing local folder:
+  the matching files and folders are downloaded there, in the same sub-folders as on the server,
+  relatively to the part of the path that has no wild chars.
+  Do not forget to quote the path so that wild chars are not expanded by your local shell.
 `,
 	Args: cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -102,41 +113,81 @@ EXAMPLES
 			fmt.Printf("Uploading %s to %s\n", from, to)
 		}
 
-		crawler, e := rest.NewCrawler(crawlerPath, isSrcLocal)
-		if e != nil {
-			log.Fatal(e)
-		}
-		nn, e := crawler.Walk()
-		if e != nil {
-			log.Fatal(e)
+		if !isSrcLocal && rest.HasGlob(crawlerPath) {
+			// Download all nodes that match the passed pattern in the target local folder
+			if rename {
+				log.Fatalf("Target folder %s must exist on the client machine when the source path contains wild chars", to)
+			}
+			sources, err := rest.ExpandGlobPaths(crawlerPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(sources) == 0 {
+				log.Fatalf("No node found on the server matching %s", crawlerPath)
+			}
+			base, err := rest.GlobBase(crawlerPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, src := range sources {
+				crawler, e := rest.NewCrawler(src, isSrcLocal)
+				if e != nil {
+					log.Fatal(e)
+				}
+				// Keep the path of the node relative to the part of the pattern without wild chars,
+				// so that files with the same name in different folders do not overwrite each other
+				rel := strings.TrimPrefix(strings.TrimPrefix(path.Dir(src), base), "/")
+				localDir := filepath.Join(targetPath, filepath.FromSlash(rel))
+				if e = os.MkdirAll(localDir, 0755); e != nil {
+					log.Fatalf("Could not create local folder %s: %s", localDir, e.Error())
+				}
+				transfer(crawler, localDir, false)
+			}
+		} else {
+			crawler, e := rest.NewCrawler(crawlerPath, isSrcLocal)
+			if e != nil {
+				log.Fatal(e)
+			}
+			transfer(crawler, targetPath, rename)
 		}
+		fmt.Println("") // Add a line to reduce glitches in the terminal
+	},
+}
 
-		targetNode := rest.NewTarget(targetPath, crawler, rename)
+// transfer effectively copies the local file or folder represented by the crawler to the server or the opposite.
+func transfer(crawler *rest.CrawlNode, targetPath string, rename bool) {
+	nn, e := crawler.Walk()
+	if e != nil {
+		log.Fatal(e)
+	}
 
-		refreshInterval := time.Millisecond * 10 // this is the default
-		if scpQuiet {
-			refreshInterval = time.Millisecond * 3000
-		}
-		pool := rest.NewBarsPool(len(nn) > 1, len(nn), refreshInterval)
-		pool.Start()
-
-		// CREATE FOLDERS
-		e = targetNode.MkdirAll(nn, pool)
-		if e != nil {
-			// Force stop of the pool that stays blocked otherwise:
-			// It is launched *before* the MkdirAll but only managed during the CopyAll phase.
-			pool.Stop()
-			log.Fatal(e)
-		}
+	targetNode := rest.NewTarget(targetPath, crawler, rename)
 
-		// UPLOAD / DOWNLOAD FILES
-		errs := targetNode.CopyAll(nn, pool)
-		//pool.Stop()
-		if len(errs) > 0 {
-			log.Fatal(errs)
-		}
-		fmt.Println("") // Add a line to reduce glitches in the terminal
-	},
+	refreshInterval := time.Millisecond * 10 // this is the default
+	if scpQuiet {
+		refreshInterval = time.Millisecond * 3000
+	}
+	pool := rest.NewBarsPool(len(nn) > 1, len(nn), refreshInterval)
+	pool.Start()
+
+	// CREATE FOLDERS
+	e = targetNode.MkdirAll(nn, pool)
+	if e != nil {
+		// Force stop of the pool that stays blocked otherwise:
+		// It is launched *before* the MkdirAll but only managed during the CopyAll phase.
+		pool.Stop()
+		log.Fatal(e)
+	}
+
+	// UPLOAD / DOWNLOAD FILES
+	// Long transfers must not fail because the token expires in the meantime
+	stopRefresh := rest.StartBackgroundRefresh()
+	errs := targetNode.CopyAll(nn, pool)
+	stopRefresh()
+	//pool.Stop()
+	if len(errs) > 0 {
+		log.Fatal(errs)
+	}
 }
 
 func targetToFullPath(from, to string) (string, bool, bool, error) {
@@ -170,34 +221,41 @@ func targetToFullPath(from, to string) (string, bool, bool, error) {
 		}
 	} else {
 		// This is local: DOWNLOAD
-		toPath, e = filepath.Abs(to)
-		if e != nil {
-			return "", false, false, e
-		}
-		_, e := os.Stat(toPath)
-		if e != nil {
+		var rename bool
+		toPath, rename, e = localTargetPath(to)
+		return toPath, false, rename, e
+	}
 
-			parPath := filepath.Dir(toPath)
-			if parPath == "." {
-				// this should never happen
-				return toPath, true, false, fmt.Errorf("target path %s does not exist on client machine, please double check and correct. ", toPath)
-			}
+	return toPath, isRemote, false, nil
+}
 
-			// Check if parent exists. In such case, we rename the file or root folder that has been passed as remote source
-			if ln, err2 := os.Stat(parPath); err2 != nil {
-				// Target parent folder does not exist on client machine, we do not create it
-				return "", true, false, fmt.Errorf("target parent folder %s does not exist in client machine. ", parPath)
-			} else if !ln.IsDir() {
-				// Local parent is not a folder
-				return "", true, false, fmt.Errorf("target parent %s is not a folder, could not download to it. ", parPath)
-			} else {
-				// Parent folder exists on local, we rename src file or folder
-				return toPath, false, true, nil
-			}
-		}
+// localTargetPath computes the absolute path of a download target on the client machine.
+// It also returns true if the last segment of the path must be used to rename the downloaded file or folder.
+func localTargetPath(to string) (string, bool, error) {
+	toPath, e := filepath.Abs(to)
+	if e != nil {
+		return "", false, e
+	}
+	if _, e = os.Stat(toPath); e == nil {
+		return toPath, false, nil
 	}
 
-	return toPath, isRemote, false, nil
+	parPath := filepath.Dir(toPath)
+	if parPath == "." {
+		// this should never happen
+		return toPath, false, fmt.Errorf("target path %s does not exist on client machine, please double check and correct. ", toPath)
+	}
+
+	// Check if parent exists. In such case, we rename the file or root folder that has been passed as remote source
+	if ln, err2 := os.Stat(parPath); err2 != nil {
+		// Target parent folder does not exist on client machine, we do not create it
+		return "", false, fmt.Errorf("target parent folder %s does not exist in client machine. ", parPath)
+	} else if !ln.IsDir() {
+		// Local parent is not a folder
+		return "", false, fmt.Errorf("target parent %s is not a folder, could not download to it. ", parPath)
+	}
+	// Parent folder exists on local, we rename src file or folder
+	return toPath, true, nil
 }
 
 func init() {
@@ -5,9 +5,31 @@ import (
 	"log"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/pydio/cells-client/v2/rest"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	linkLabel          string
+	linkDescription    string
+	linkPassword       string
+	linkNoPassword     bool
+	linkHash           string
+	linkExpires        string
+	linkMaxDownloads   string
+	linkUpload         bool
+	linkNoDownload     bool
+	shareLsRaw         bool
+	shareLsAllAccounts bool
 )
 
 var shareNode = &cobra.Command{
@@ -17,6 +39,8 @@ var shareNode = &cobra.Command{
 DESCRIPTION
 
   Create a public link that adds public access to the passed path on the server.
+  By default, the link can be used to preview and download the shared resource, with no password and no expiration date.
+  Use the flags to fine-tune the link and the sub-commands to manage the links that you have already created.
 
 EXAMPLES
 
@@ -24,6 +48,13 @@ EXAMPLES
   $ ` + os.Args[0] + ` share common-files/MyPublicImage.jpg
   Public link created at https://pydio.example.com/public/479cc5dbdf8b
 
+  2/ Create a password protected link with a custom hash, that expires in 2 weeks and can be downloaded 10 times
+  $ ` + os.Args[0] + ` share common-files/Contract.pdf --link-password 'S3cr3t!' --hash acme-contract --expires 14d --max-downloads 10
+  Public link created at https://pydio.example.com/public/acme-contract
+
+  3/ Create a link that lets your customer drop files in a folder
+  $ ` + os.Args[0] + ` share common-files/inbox --upload --label "ACME inbox" --description "Put your files here"
+
 `,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -37,7 +68,11 @@ EXAMPLES
 			return
 		}
 
-		l, err := rest.CreateSimpleFolderLink(node.UUID, path.Base(p))
+		opts := linkOptionsFromFlags()
+		if opts.Label == "" {
+			opts.Label = path.Base(p)
+		}
+		l, err := rest.CreateShareLink(node, opts)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -47,6 +82,279 @@ EXAMPLES
 	},
 }
 
+var shareLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the public links",
+	Long: `
+DESCRIPTION
+
+  List the public links that you have created. Use the --all flag to also list the links
+  that have been created by other users on the resources you can access.
+
+EXAMPLES
+
+  # List your links
+  ` + os.Args[0] + ` share ls
+
+  # Only list the UUIDs of your links, e.g. to revoke them all
+  ` + os.Args[0] + ` share ls --raw | xargs -n1 ` + os.Args[0] + ` share rm
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resources, err := rest.ListSharedResources(models.ListSharedResourcesRequestListShareTypeLINKS, !shareLsAllAccounts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !shareLsRaw {
+			var links []*models.ListSharedResourcesResponseSharedResource
+			for _, r := range resources {
+				if r.Link != nil {
+					links = append(links, r)
+				}
+			}
+			if printList(links) {
+				return
+			}
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Uuid", "Label", "Path", "Link", "Password", "Expires", "Downloads"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		var count int
+		for _, r := range resources {
+			l := r.Link
+			if l == nil {
+				continue
+			}
+			count++
+			if shareLsRaw {
+				fmt.Println(l.UUID)
+				continue
+			}
+			p := ""
+			if r.Node != nil {
+				p = strings.Trim(r.Node.Path, "/")
+			}
+			table.Append([]string{l.UUID, l.Label, p, rest.StandardizeLink(l.LinkURL), yesNo(l.PasswordRequired), linkExpiry(l), linkDownloads(l)})
+		}
+		if shareLsRaw {
+			return
+		}
+		fmt.Printf("Found %d public links\n", count)
+		table.Render()
+	},
+}
+
+var shareShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the details of a public link",
+	Long: `
+DESCRIPTION
+
+  Show all settings of a public link, given its UUID as listed by the 'share ls' command.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		l, err := rest.GetShareLink(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var perms []string
+		for _, p := range l.Permissions {
+			perms = append(perms, string(*p))
+		}
+		var roots []string
+		for _, n := range l.RootNodes {
+			if n.Path != "" {
+				roots = append(roots, strings.Trim(n.Path, "/"))
+			} else {
+				roots = append(roots, n.UUID)
+			}
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		table.AppendBulk([][]string{
+			{"Uuid", l.UUID},
+			{"Label", l.Label},
+			{"Description", l.Description},
+			{"Link", rest.StandardizeLink(l.LinkURL)},
+			{"Hash", l.LinkHash},
+			{"Shared nodes", strings.Join(roots, ", ")},
+			{"Permissions", strings.Join(perms, ", ")},
+			{"Password", yesNo(l.PasswordRequired)},
+			{"Expires", linkExpiry(l)},
+			{"Downloads", linkDownloads(l)},
+			{"Owner", l.UserLogin},
+		})
+		table.Render()
+	},
+}
+
+var shareUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modify an existing public link",
+	Long: `
+DESCRIPTION
+
+  Modify the settings of a public link, given its UUID as listed by the 'share ls' command.
+  Only the settings that are passed via flags are changed. Pass 0 or none to --expires or --max-downloads
+  to remove the expiration date or the download limit.
+
+EXAMPLES
+
+  # Extend the validity of a link and protect it with a password
+  ` + os.Args[0] + ` share update 0e8ba1c3-d9e4-4b3c-8fb1-9c3f15bd8d67 --expires 2024-12-31 --link-password 'S3cr3t!'
+
+  # Remove the password of a link
+  ` + os.Args[0] + ` share update 0e8ba1c3-d9e4-4b3c-8fb1-9c3f15bd8d67 --no-password
+
+  # Remove the expiration date and the download limit of a link
+  ` + os.Args[0] + ` share update 0e8ba1c3-d9e4-4b3c-8fb1-9c3f15bd8d67 --expires none --max-downloads none
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		l, err := rest.GetShareLink(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if linkNoPassword && linkPassword != "" {
+			log.Fatal("Please use either --link-password or --no-password, not both")
+		}
+
+		opts := linkOptionsFromFlags()
+		opts.UpdatePermissions = cmd.Flags().Changed("upload") || cmd.Flags().Changed("no-download")
+		updated, err := rest.UpdateShareLink(l, opts, linkNoPassword)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Public link updated at " + rest.StandardizeLink(updated.LinkURL))
+	},
+}
+
+var shareRmCmd = &cobra.Command{
+	Use:   "rm",
+	Short: "Revoke public links",
+	Long: `
+DESCRIPTION
+
+  Delete the public links with the passed UUIDs, as listed by the 'share ls' command:
+  the links cannot be used anymore. The shared resources are left untouched.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var failed bool
+		for _, id := range args {
+			if err := rest.DeleteShareLink(id); err != nil {
+				fmt.Println(err.Error())
+				failed = true
+				continue
+			}
+			fmt.Printf("Link %s has been revoked\n", id)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// addLinkFlags registers the flags that define the settings of a public link.
+func addLinkFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&linkLabel, "label", "", "Label of the link, defaults to the name of the shared resource")
+	flags.StringVar(&linkDescription, "description", "", "Description of the link, shown to the visitors")
+	// Do not use --password, that would shadow the global flag that is used to authenticate
+	flags.StringVar(&linkPassword, "link-password", "", "Protect the link with this password")
+	flags.StringVar(&linkHash, "hash", "", "Use a custom hash in the link URL, instead of a technical ID")
+	flags.StringVar(&linkExpires, "expires", "", "Expiration of the link, either a date (YYYY-MM-DD) or a duration (e.g: 48h, 30d), 0 or none for no expiration")
+	flags.StringVar(&linkMaxDownloads, "max-downloads", "", "Maximum number of downloads that are allowed with this link, 0 or none for no limit")
+	flags.BoolVar(&linkUpload, "upload", false, "Also allow visitors to upload files (folders only)")
+	flags.BoolVar(&linkNoDownload, "no-download", false, "Only allow visitors to preview the resource, not to download it")
+}
+
+func linkOptionsFromFlags() *rest.ShareLinkOptions {
+	opts := &rest.ShareLinkOptions{
+		Label:       linkLabel,
+		Description: linkDescription,
+		Password:    linkPassword,
+		CustomHash:  linkHash,
+		Upload:      linkUpload,
+		NoDownload:  linkNoDownload,
+	}
+	switch linkExpires {
+	case "":
+	case "0", "none":
+		opts.ClearExpiry = true
+	default:
+		t, err := parseExpiry(linkExpires)
+		if err != nil {
+			log.Fatalf("Invalid value for --expires: %s", err.Error())
+		}
+		opts.ExpireAt = t
+	}
+	switch linkMaxDownloads {
+	case "":
+	case "0", "none":
+		opts.ClearMaxDownloads = true
+	default:
+		m, err := strconv.ParseInt(linkMaxDownloads, 10, 64)
+		if err != nil || m < 0 {
+			log.Fatalf("Invalid value for --max-downloads: %s is not a positive number", linkMaxDownloads)
+		}
+		opts.MaxDownloads = m
+	}
+	return opts
+}
+
+// parseExpiry accepts either a date in the YYYY-MM-DD format or a duration from now.
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither a date (YYYY-MM-DD) nor a duration", s)
+	}
+	return time.Now().Add(d), nil
+}
+
+func linkExpiry(l *models.RestShareLink) string {
+	if i, e := strconv.ParseInt(l.AccessEnd, 10, 64); e == nil && i > 0 {
+		return time.Unix(i, 0).Format("2006-01-02 15:04")
+	}
+	return "Never"
+}
+
+func linkDownloads(l *models.RestShareLink) string {
+	current := l.CurrentDownloads
+	if current == "" {
+		current = "0"
+	}
+	if l.MaxDownloads == "" || l.MaxDownloads == "0" {
+		return current
+	}
+	return current + "/" + l.MaxDownloads
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
 func init() {
+	addLinkFlags(shareNode.Flags())
+	addLinkFlags(shareUpdateCmd.Flags())
+	shareUpdateCmd.Flags().BoolVar(&linkNoPassword, "no-password", false, "Remove the password protection of the link")
+	shareLsCmd.Flags().BoolVarP(&shareLsRaw, "raw", "r", false, "Only list the UUIDs of the links, one per line")
+	shareLsCmd.Flags().BoolVarP(&shareLsAllAccounts, "all", "a", false, "Also list the links that have been created by other users")
+
+	shareNode.AddCommand(shareLsCmd)
+	shareNode.AddCommand(shareShowCmd)
+	shareNode.AddCommand(shareUpdateCmd)
+	shareNode.AddCommand(shareRmCmd)
 	RootCmd.AddCommand(shareNode)
 }
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	trashRaw       bool
+	trashOlderThan string
+	trashForce     bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage the recycle bins of your workspaces",
+	Long: `
+DESCRIPTION
+
+  When you remove files or folders with the 'rm' command, they are moved to the recycle bin
+  that is at the root of the corresponding workspace.
+  Use the sub-commands of 'trash' to list the trashed items, restore them to their original location
+  or remove them permanently.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var trashLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the content of the recycle bins",
+	Long: `
+DESCRIPTION
+
+  List the items that are in the recycle bin of the given workspace, or of all your workspaces if none is given.
+  For each item, the location it has been deleted from is also shown.
+  The date is the last modification date known by the server, that is, in most cases, the deletion date.
+
+EXAMPLES
+
+  # List the recycle bins of all your workspaces
+  ` + os.Args[0] + ` trash ls
+
+  # Only list the paths of the items that are in the recycle bin of the common-files workspace
+  ` + os.Args[0] + ` trash ls common-files --raw
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ws := ""
+		if len(args) > 0 {
+			ws = args[0]
+		}
+		items := listTrash(ws)
+
+		if trashRaw {
+			for _, item := range items {
+				fmt.Println(strings.Trim(item.Node.Path, "/"))
+			}
+			return
+		}
+		if printList(items) {
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Type", "Path", "Original location", "Size", "Deleted"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		for _, item := range items {
+			t := "File"
+			if isTrashedFolder(item) {
+				t = "Folder"
+			}
+			original := item.OriginalPath
+			if original == "" {
+				original = "-"
+			}
+			table.Append([]string{t, strings.Trim(item.Node.Path, "/"), original, sizeToBytes(item.Node.Size), stampToDate(item.Node.MTime)})
+		}
+		fmt.Printf("Found %d items in the recycle bin\n", len(items))
+		table.Render()
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore trashed items to their original location",
+	Long: `
+DESCRIPTION
+
+  Move the passed items from the recycle bin back to the location they have been deleted from.
+  Items are designated by their path in the recycle bin, as listed by the 'trash ls' command.
+  Paths support the same wild chars as the 'cp' command.
+
+EXAMPLES
+
+  # Restore a single file
+  ` + os.Args[0] + ` trash restore common-files/recycle_bin/report.pdf
+
+  # Restore all PDF files that are in the recycle bin of the common-files workspace
+  ` + os.Args[0] + ` trash restore 'common-files/recycle_bin/*.pdf'
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		var targets []string
+		for _, arg := range args {
+			arg = strings.Trim(arg, "/")
+			if !rest.HasGlob(arg) {
+				if _, ok := rest.StatNode(arg); !ok {
+					log.Fatalf("No item found at %s", arg)
+				}
+				targets = append(targets, arg)
+				continue
+			}
+			matches, err := rest.ExpandGlobPaths(arg)
+			if err != nil {
+				log.Fatalf("Could not expand %s, aborting. Cause: %s\n", arg, err.Error())
+			}
+			if len(matches) == 0 {
+				log.Fatalf("No item found matching %s", arg)
+			}
+			targets = append(targets, matches...)
+		}
+		for _, t := range targets {
+			if !rest.IsInTrash(t) {
+				log.Fatalf("%s is not in a recycle bin, only items that are directly in the recycle bin of a workspace can be restored", t)
+			}
+		}
+
+		jobIDs, err := rest.RestoreNodes(targets)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !monitorJobs(jobIDs) {
+			os.Exit(1)
+		}
+		fmt.Printf("%d items have been restored\n", len(targets))
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete the items of the recycle bins",
+	Long: `
+DESCRIPTION
+
+  Permanently delete the items that are in the recycle bin of the given workspace,
+  or of all your workspaces if none is given. This cannot be undone.
+
+  Use the --older-than flag to only delete items that have been trashed for a given time.
+  The duration is expressed with the usual units ('h', 'm', 's'), plus 'd' for days, e.g: '36h' or '30d'.
+
+EXAMPLES
+
+  # Empty the recycle bin of the common-files workspace
+  ` + os.Args[0] + ` trash empty common-files
+
+  # Delete the items that have been trashed more than 30 days ago in all workspaces, without confirmation
+  ` + os.Args[0] + ` trash empty --older-than 30d --force
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ws := ""
+		if len(args) > 0 {
+			ws = args[0]
+		}
+
+		var limit time.Time
+		if trashOlderThan != "" {
+			age, err := parseAge(trashOlderThan)
+			if err != nil {
+				log.Fatalf("Invalid value for --older-than: %s", err.Error())
+			}
+			limit = time.Now().Add(-age)
+		}
+
+		var targets []string
+		for _, item := range listTrash(ws) {
+			if !limit.IsZero() {
+				mtime, e := strconv.ParseInt(item.Node.MTime, 10, 64)
+				if e != nil || time.Unix(mtime, 0).After(limit) {
+					continue
+				}
+			}
+			targets = append(targets, strings.Trim(item.Node.Path, "/"))
+		}
+		if len(targets) == 0 {
+			fmt.Println("Nothing to delete")
+			return
+		}
+
+		if !trashForce {
+			p := promptui.Select{Label: fmt.Sprintf("%d items will be permanently deleted, are you sure", len(targets)), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+
+		jobIDs, err := rest.DeleteNode(targets, true)
+		if err != nil {
+			log.Fatalf("Could not empty the recycle bin, cause: %s\n", err.Error())
+		}
+		if !monitorJobs(jobIDs) {
+			os.Exit(1)
+		}
+		fmt.Printf("%d items have been permanently deleted\n", len(targets))
+	},
+}
+
+func listTrash(ws string) []*rest.TrashedNode {
+	items, err := rest.ListTrash(ws)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return items
+}
+
+func isTrashedFolder(item *rest.TrashedNode) bool {
+	return item.Node.Type != nil && *item.Node.Type == models.TreeNodeTypeCOLLECTION
+}
+
+// parseAge parses a duration, also supporting a number of days with the 'd' unit.
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("could not parse %s as a number of days", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// monitorJobs waits for the end of the passed jobs and returns false if at least one of them has failed.
+func monitorJobs(jobIDs []string) bool {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ok := true
+	for _, id := range jobIDs {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if err := rest.MonitorJob(id); err != nil {
+				log.Printf("Job %s has failed: %s\n", id, err.Error())
+				mu.Lock()
+				ok = false
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+	return ok
+}
+
+func init() {
+	trashLsCmd.Flags().BoolVarP(&trashRaw, "raw", "r", false, "Only list the paths of the trashed items, one per line")
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only delete the items that have been trashed before this duration, e.g: 30d")
+	trashEmptyCmd.Flags().BoolVarP(&trashForce, "force", "f", false, "Do not ask for user approval")
+
+	trashCmd.AddCommand(trashLsCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	RootCmd.AddCommand(trashCmd)
+}
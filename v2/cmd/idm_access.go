@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var accessAs string
+
+var accessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Show who can access a path",
+	Long: `
+DESCRIPTION
+
+  Audit the permissions on a path: the ACLs defined on the node, on its parent folders and on the roots
+  of its workspace are gathered, the roles that hold them are expanded to their users and groups,
+  and the effective read, write and deny rights of each principal are printed.
+
+  With the --as flag, only answer the question for a given user, taking all the roles of the user into account.
+
+  Rights are resolved the way the server does: for a given node, the last role of a user wins, rights
+  defined on a sub-folder override the rights of its parents, and a deny anywhere on the way blocks the access.
+  This is an approximation: policies and rights that depend on the context of a request are not evaluated.
+
+EXAMPLES
+
+  # List everyone who has access to a folder
+  ` + os.Args[0] + ` idm access common-files/reports
+
+  # Can alice write here?
+  ` + os.Args[0] + ` idm access common-files/reports --as alice
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		report, err := rest.CollectAccess(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if accessAs != "" {
+			printUserAccess(report, args[0], accessAs)
+			return
+		}
+		printAccessTable(report)
+	},
+}
+
+type accessPrincipal struct {
+	name   string
+	kind   string
+	roles  []string
+	rights rest.Rights
+}
+
+func printUserAccess(report *rest.AccessReport, nodePath, login string) {
+	u, err := rest.FindUser(login)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rights := report.Resolve(rest.UserRoleIDs(u))
+	switch {
+	case rights.Deny:
+		fmt.Printf("%s is denied access to %s (deny defined on %s)\n", login, nodePath, rights.DefinedOn)
+	case rights.Write:
+		fmt.Printf("%s can read and write %s (rights defined on %s)\n", login, nodePath, rights.DefinedOn)
+	case rights.Read:
+		fmt.Printf("%s can read but not write %s (rights defined on %s)\n", login, nodePath, rights.DefinedOn)
+	default:
+		fmt.Printf("%s has no access to %s\n", login, nodePath)
+	}
+}
+
+func printAccessTable(report *rest.AccessReport) {
+	roles, err := rest.ListRoles(true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	labels := map[string]string{}
+	for _, r := range roles {
+		labels[r.UUID] = r.Label
+	}
+
+	principals := map[string]*accessPrincipal{}
+	addPrincipal := func(key, name, kind, via string, roleIDs []string) {
+		p, ok := principals[key]
+		if !ok {
+			p = &accessPrincipal{name: name, kind: kind, rights: report.Resolve(roleIDs)}
+			principals[key] = p
+		}
+		p.roles = append(p.roles, via)
+	}
+
+	for _, roleID := range report.RoleIDs() {
+		via := labels[roleID]
+		if via == "" {
+			via = roleID
+		}
+		if roleID == rest.RootGroupRole {
+			addPrincipal("group:/", "/ (all users)", "group", via, []string{rest.RootGroupRole})
+			continue
+		}
+		users, groups, e := rest.RoleMembers(roleID)
+		if e != nil {
+			log.Fatal(e)
+		}
+		for _, u := range users {
+			addPrincipal("user:"+u.Login, u.Login, "user", via, rest.UserRoleIDs(u))
+		}
+		for _, g := range groups {
+			addPrincipal("group:"+rest.GroupFullPath(g), rest.GroupFullPath(g), "group", via, rest.UserRoleIDs(g))
+		}
+	}
+
+	if len(principals) == 0 {
+		fmt.Printf("No rights are defined on %s\n", report.Levels[len(report.Levels)-1].Path)
+		return
+	}
+
+	var keys []string
+	for k := range principals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Principal", "Kind", "Via role", "Read", "Write", "Deny", "Defined on"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, k := range keys {
+		p := principals[k]
+		table.Append([]string{
+			p.name,
+			p.kind,
+			strings.Join(p.roles, ", "),
+			yesNo(p.rights.Read && !p.rights.Deny),
+			yesNo(p.rights.Write && !p.rights.Deny),
+			yesNo(p.rights.Deny),
+			p.rights.DefinedOn,
+		})
+	}
+	table.Render()
+}
+
+func init() {
+	accessCmd.Flags().StringVar(&accessAs, "as", "", "Only show the rights of the user with this login")
+	idmCmd.AddCommand(accessCmd)
+}
@@ -54,6 +54,10 @@ DESCRIPTION
 			log.Fatal(err)
 		}
 
+		if printList(result.Payload.ACLs) {
+			return
+		}
+
 		//prints the workspace label
 		if len(result.Payload.ACLs) > 0 {
 			fmt.Printf("* %d ACLs found\n", len(result.Payload.ACLs))
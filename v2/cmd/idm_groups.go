@@ -3,7 +3,12 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
+	"path"
+	"strings"
 
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	"github.com/pydio/cells-sdk-go/v3/client/user_service"
@@ -37,6 +42,10 @@ DESCRIPION
 			log.Fatal(err)
 		}
 
+		if printList(result.Payload.Groups) {
+			return
+		}
+
 		if len(result.Payload.Groups) > 0 {
 			fmt.Printf("Found %d groups\n", len(result.Payload.Groups))
 			for _, u := range result.Payload.Groups {
@@ -46,6 +55,224 @@ DESCRIPION
 	},
 }
 
+var (
+	groupDisplayName string
+	groupRecursive   bool
+	groupForce       bool
+	groupRemoveTo    string
+)
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage groups and their members",
+	Long: `
+DESCRIPTION
+
+  Manage the hierarchy of groups and their members.
+  Groups are designated by their full path, e.g: /engineering/backend.
+
+  Note that in Cells, a user belongs to exactly one group: adding a user to a group
+  moves it from its current group, and removing it from a group moves it to the root group (or to the group passed with --to).
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var groupTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the hierarchy of groups",
+	Long: `
+DESCRIPTION
+
+  Show the hierarchy of groups, starting from the passed group or from the root if none is given.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		root := "/"
+		if len(args) > 0 {
+			root = "/" + strings.Trim(args[0], "/")
+		}
+		groups, err := rest.ListGroups(root, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if printList(groups) {
+			return
+		}
+		fmt.Println(root)
+		rootDepth := len(strings.Split(strings.Trim(root, "/"), "/"))
+		if root == "/" {
+			rootDepth = 0
+		}
+		for _, g := range groups {
+			full := rest.GroupFullPath(g)
+			depth := len(strings.Split(strings.Trim(full, "/"), "/")) - rootDepth
+			line := strings.Repeat("    ", depth-1) + "└── " + path.Base(full)
+			if dn := g.Attributes[rest.UserAttrDisplayName]; dn != "" && dn != path.Base(full) {
+				line += " (" + dn + ")"
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a group",
+	Long: `
+DESCRIPTION
+
+  Create a group at the passed full path. The parent group must already exist.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm group create /engineering/backend --display-name "Backend Team"
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		g, err := rest.CreateGroup(args[0], groupDisplayName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Group %s has been created with ID %s\n", rest.GroupFullPath(g), g.UUID)
+	},
+}
+
+var groupMoveCmd = &cobra.Command{
+	Use:   "move",
+	Short: "Move a group under another parent group",
+	Long: `
+DESCRIPTION
+
+  Move a group, with its users and sub-groups, under another parent group.
+
+EXAMPLES
+
+  # Move the /engineering/backend group to /platform/backend
+  ` + os.Args[0] + ` idm group move /engineering/backend /platform
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cm *cobra.Command, args []string) {
+		g, err := rest.MoveGroup(args[0], args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Group has been moved to %s\n", rest.GroupFullPath(g))
+	},
+}
+
+var groupDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a group",
+	Long: `
+DESCRIPTION
+
+  Delete a group. WARNING: all its users and sub-groups are also deleted.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if !groupForce {
+			p := promptui.Select{Label: fmt.Sprintf("Delete %s with all its users and sub-groups", args[0]), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+		if err := rest.DeleteGroup(args[0]); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Group %s has been deleted\n", args[0])
+	},
+}
+
+var groupMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "List the users of a group",
+	Long: `
+DESCRIPTION
+
+  List the users of a group. Use the --recursive flag to also list the users of its sub-groups.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		users, err := rest.ListGroupMembers(args[0], groupRecursive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if printList(users) {
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Login", "Display name", "Email", "Group"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, u := range users {
+			table.Append([]string{u.Login, u.Attributes[rest.UserAttrDisplayName], u.Attributes[rest.UserAttrEmail], u.GroupPath})
+		}
+		fmt.Printf("Found %d users in %s\n", len(users), args[0])
+		table.Render()
+	},
+}
+
+var groupAddUserCmd = &cobra.Command{
+	Use:   "add-user",
+	Short: "Move users into a group",
+	Long: `
+DESCRIPTION
+
+  Move the users with the passed logins into the group. Users leave their current group.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm group add-user /engineering/backend alice bob
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cm *cobra.Command, args []string) {
+		groupPath := args[0]
+		reportPerUser(args[1:], func(login string) error {
+			return rest.SetUserGroup(login, groupPath)
+		}, "added to "+groupPath)
+	},
+}
+
+var groupRemoveUserCmd = &cobra.Command{
+	Use:   "remove-user",
+	Short: "Remove users from a group",
+	Long: `
+DESCRIPTION
+
+  Remove the users with the passed logins from the group: they are moved to the root group,
+  or to the group passed with the --to flag.
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cm *cobra.Command, args []string) {
+		groupPath := "/" + strings.Trim(args[0], "/")
+		reportPerUser(args[1:], func(login string) error {
+			u, err := rest.FindUser(login)
+			if err != nil {
+				return err
+			}
+			if path.Join("/", u.GroupPath) != groupPath {
+				return fmt.Errorf("user is not a member of %s", groupPath)
+			}
+			return rest.SetUserGroup(login, groupRemoveTo)
+		}, "removed from "+groupPath)
+	},
+}
+
 func init() {
+	groupCreateCmd.Flags().StringVar(&groupDisplayName, "display-name", "", "Display name of the group")
+	groupMembersCmd.Flags().BoolVarP(&groupRecursive, "recursive", "r", false, "Also list the users of the sub-groups")
+	groupDeleteCmd.Flags().BoolVarP(&groupForce, "force", "f", false, "Do not ask for user approval")
+	groupRemoveUserCmd.Flags().StringVar(&groupRemoveTo, "to", "/", "Group the users are moved to")
+
+	groupCmd.AddCommand(groupTreeCmd)
+	groupCmd.AddCommand(groupCreateCmd)
+	groupCmd.AddCommand(groupMoveCmd)
+	groupCmd.AddCommand(groupDeleteCmd)
+	groupCmd.AddCommand(groupMembersCmd)
+	groupCmd.AddCommand(groupAddUserCmd)
+	groupCmd.AddCommand(groupRemoveUserCmd)
+	idmCmd.AddCommand(groupCmd)
 	idmCmd.AddCommand(listGroups)
 }
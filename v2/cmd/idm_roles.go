@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
 
+	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 
 	"github.com/pydio/cells-sdk-go/v3/client/role_service"
@@ -39,6 +41,10 @@ DESCRIPTION
 			log.Fatal(err)
 		}
 
+		if printList(result.Payload.Roles) {
+			return
+		}
+
 		if len(result.Payload.Roles) > 0 {
 			fmt.Printf("Found %d roles\n", len(result.Payload.Roles))
 			for _, u := range result.Payload.Roles {
@@ -56,6 +62,197 @@ DESCRIPTION
 	},
 }
 
+var (
+	roleID          string
+	roleAutoApplies []string
+	roleUsers       []string
+	roleGroups      []string
+	roleWorkspace   string
+	rolePerm        string
+	roleForce       bool
+)
+
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "Manage roles, their members and their rights",
+	Long: `
+DESCRIPTION
+
+  Create and delete roles, assign them to users and groups, and define the rights they grant on workspaces.
+  Roles are designated by their UUID or by their label.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var roleCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a role",
+	Long: `
+DESCRIPTION
+
+  Create a role with the passed label. A random UUID is used unless one is given with the --uuid flag.
+  Use the --auto-apply flag to automatically apply the role to all users with a given profile.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm role create "Project X Members" --uuid project-x
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		r, err := rest.CreateRole(args[0], roleID, roleAutoApplies)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Role %s has been created with ID %s\n", r.Label, r.UUID)
+	},
+}
+
+var roleDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a role",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		r := findRole(args[0])
+		if r.UserRole || r.GroupRole {
+			log.Fatalf("%s is the technical role of a user or a group, it cannot be deleted", args[0])
+		}
+		if !roleForce {
+			p := promptui.Select{Label: fmt.Sprintf("Delete role %s", r.Label), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+		if err := rest.DeleteRole(r.UUID); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Role %s has been deleted\n", r.Label)
+	},
+}
+
+var roleAssignCmd = &cobra.Command{
+	Use:   "assign",
+	Short: "Assign a role to users and groups",
+	Long: `
+DESCRIPTION
+
+  Add the role to the roles of the passed users and groups. Users of a group inherit the roles of the group.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm role assign project-x --user alice --user bob --group /engineering/backend
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		assignRole(findRole(args[0]), true)
+	},
+}
+
+var roleUnassignCmd = &cobra.Command{
+	Use:   "unassign",
+	Short: "Remove a role from users and groups",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		assignRole(findRole(args[0]), false)
+	},
+}
+
+var roleAclCmd = &cobra.Command{
+	Use:   "acl",
+	Short: "Manage the rights that a role grants",
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var roleAclSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Define the rights that a role grants on a workspace",
+	Long: `
+DESCRIPTION
+
+  Replace the rights that the role grants on a workspace. Supported permissions are:
+   - r, w or rw: read and/or write access,
+   - deny: explicitly deny access, even if other roles grant it,
+   - none: remove all rights of the role on the workspace.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm role acl set project-x --workspace project-x-files --perm rw
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if roleWorkspace == "" {
+			log.Fatal("Please provide a workspace with the --workspace flag")
+		}
+		if rolePerm == "" {
+			log.Fatal("Please provide the permission with the --perm flag")
+		}
+		r := findRole(args[0])
+		if err := rest.SetWorkspaceRights(r.UUID, roleWorkspace, rolePerm); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Rights of %s on %s have been set to %s\n", r.Label, roleWorkspace, rolePerm)
+	},
+}
+
+func findRole(id string) *models.IdmRole {
+	r, err := rest.FindRole(id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return r
+}
+
+func assignRole(r *models.IdmRole, assign bool) {
+	if len(roleUsers) == 0 && len(roleGroups) == 0 {
+		log.Fatal("Please provide at least one user or group with the --user and --group flags")
+	}
+	done := "assigned"
+	if !assign {
+		done = "unassigned"
+	}
+	var failed int
+	for _, login := range roleUsers {
+		if err := rest.AssignRoleToUser(r, login, assign); err != nil {
+			failed++
+			fmt.Printf("%s user %s: %s\n", promptui.IconBad, login, err.Error())
+			continue
+		}
+		fmt.Printf("%s role %s %s to user %s\n", promptui.IconGood, r.Label, done, login)
+	}
+	for _, g := range roleGroups {
+		if err := rest.AssignRoleToGroup(r, g, assign); err != nil {
+			failed++
+			fmt.Printf("%s group %s: %s\n", promptui.IconBad, g, err.Error())
+			continue
+		}
+		fmt.Printf("%s role %s %s to group %s\n", promptui.IconGood, r.Label, done, g)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
 func init() {
+	roleCreateCmd.Flags().StringVar(&roleID, "uuid", "", "UUID of the new role, a random one is used if empty")
+	roleCreateCmd.Flags().StringSliceVar(&roleAutoApplies, "auto-apply", []string{}, "Automatically apply the role to users with these profiles, e.g: standard")
+	roleDeleteCmd.Flags().BoolVarP(&roleForce, "force", "f", false, "Do not ask for user approval")
+	for _, c := range []*cobra.Command{roleAssignCmd, roleUnassignCmd} {
+		c.Flags().StringArrayVar(&roleUsers, "user", []string{}, "Login of a user, can be repeated")
+		c.Flags().StringArrayVar(&roleGroups, "group", []string{}, "Full path of a group, can be repeated")
+	}
+	roleAclSetCmd.Flags().StringVar(&roleWorkspace, "workspace", "", "Slug or UUID of the workspace")
+	roleAclSetCmd.Flags().StringVar(&rolePerm, "perm", "", "Permission to grant: r, w, rw, deny or none")
+
+	roleAclCmd.AddCommand(roleAclSetCmd)
+	roleCmd.AddCommand(roleCreateCmd)
+	roleCmd.AddCommand(roleDeleteCmd)
+	roleCmd.AddCommand(roleAssignCmd)
+	roleCmd.AddCommand(roleUnassignCmd)
+	roleCmd.AddCommand(roleAclCmd)
+	idmCmd.AddCommand(roleCmd)
 	idmCmd.AddCommand(listRoles)
 }
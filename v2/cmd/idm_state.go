@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	stateFile   string
+	statePrune  bool
+	stateDryRun bool
+	stateYes    bool
+)
+
+var idmApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a manifest that describes users, groups, roles and workspaces",
+	Long: `
+DESCRIPTION
+
+  Bring the server to the state that is described in a YAML or JSON manifest: first, the manifest is compared
+  with the current state of the server and the required changes are listed, then they are applied once you approve them.
+
+  Settings that are not defined in the manifest are left untouched. Objects that exist on the server but are not
+  described in the manifest are only deleted with the --prune flag, and only for the kinds of objects that
+  are listed in the manifest. The current user, the built-in roles and the common-files and personal-files
+  workspaces are never deleted.
+
+  Use 'idm export' to get a manifest of the current state of the server.
+
+MANIFEST
+
+  groups:
+    - path: /engineering
+      displayName: Engineering
+  users:
+    - login: alice
+      password: changeme     # only used when the user is created
+      email: alice@example.com
+      groupPath: /engineering
+      profile: standard
+      roles: [project-x]
+  roles:
+    - id: project-x
+      label: Project X Members
+      rights:
+        project-x-files: rw  # r, w, rw or deny
+  workspaces:
+    - slug: project-x-files
+      label: Project X
+      roots: [pydiods1/projects/x]
+      defaultRights: ""
+
+EXAMPLES
+
+  # Review the changes without applying them
+  ` + os.Args[0] + ` idm apply -f state.yaml --dry-run
+
+  # Apply the changes and delete everything that is not in the manifest
+  ` + os.Args[0] + ` idm apply -f state.yaml --prune
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		if stateFile == "" {
+			log.Fatal("Please provide a manifest with the --file flag")
+		}
+		desired, err := readState(stateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		changes, err := rest.PlanState(desired, statePrune)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(changes) == 0 {
+			fmt.Println("Nothing to do, the server is up to date")
+			return
+		}
+
+		var created, updated, deleted int
+		for _, c := range changes {
+			fmt.Println(c.String())
+			switch c.Action {
+			case rest.ChangeCreate:
+				created++
+			case rest.ChangeUpdate:
+				updated++
+			case rest.ChangeDelete:
+				deleted++
+			}
+		}
+		fmt.Printf("\nPlan: %d to create, %d to update, %d to delete\n", created, updated, deleted)
+		if stateDryRun {
+			return
+		}
+		if !stateYes {
+			p := promptui.Select{Label: "Apply these changes", Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing has been changed")
+				return
+			}
+		}
+
+		var failed int
+		for _, c := range changes {
+			if err := c.Apply(); err != nil {
+				failed++
+				fmt.Printf("%s %s %s: %s\n", promptui.IconBad, c.Kind, c.ID, err.Error())
+				continue
+			}
+			fmt.Printf("%s %s %s %sd\n", promptui.IconGood, c.Kind, c.ID, strings.TrimSuffix(c.Action, "e"))
+		}
+		fmt.Printf("%d changes applied, %d failed\n", len(changes)-failed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var idmExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export users, groups, roles and workspaces as a manifest",
+	Long: `
+DESCRIPTION
+
+  Print the current users, groups, roles and workspaces of the server, in the schema that is used by 'idm apply'.
+  Technical roles of users and groups are not exported, nor are the workspaces that are not managed by administrators.
+  Passwords are never exported.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm export > state.yaml
+  ` + os.Args[0] + ` idm export -o json > state.json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		state, err := rest.ExportState()
+		if err != nil {
+			log.Fatal(err)
+		}
+		handled, err := printStructured(os.Stdout, state)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !handled {
+			// A manifest has no table form: default to YAML
+			enc := yaml.NewEncoder(os.Stdout)
+			enc.SetIndent(2)
+			if err = enc.Encode(state); err != nil {
+				log.Fatal(err)
+			}
+			enc.Close()
+		}
+	},
+}
+
+// readState parses a YAML or JSON manifest, depending on its extension. Unknown keys are rejected.
+func readState(file string) (*rest.IdmState, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	state := &rest.IdmState{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(state)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(state)
+	default:
+		return nil, fmt.Errorf("unsupported file %s, only .yaml, .yml and .json files are supported", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", file, err.Error())
+	}
+	return state, nil
+}
+
+func init() {
+	idmApplyCmd.Flags().StringVarP(&stateFile, "file", "f", "", "Path to the YAML or JSON manifest")
+	idmApplyCmd.Flags().BoolVar(&statePrune, "prune", false, "Delete the objects that are not described in the manifest")
+	idmApplyCmd.Flags().BoolVar(&stateDryRun, "dry-run", false, "Only show the changes, do not apply them")
+	idmApplyCmd.Flags().BoolVarP(&stateYes, "yes", "y", false, "Apply the changes without asking for approval")
+
+	idmCmd.AddCommand(idmApplyCmd)
+	idmCmd.AddCommand(idmExportCmd)
+}
@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/pydio/cells-sdk-go/v3/client/user_service"
 
@@ -38,6 +46,10 @@ DESCRIPTION
 			log.Fatal(err)
 		}
 
+		if printList(result.Payload.Users) {
+			return
+		}
+
 		//prints the login of the users retrieved previously
 		if len(result.Payload.Users) > 0 {
 			fmt.Printf("Found %d users\n", len(result.Payload.Users))
@@ -49,6 +61,292 @@ DESCRIPTION
 	},
 }
 
+var (
+	userDef      = &rest.UserDefinition{}
+	userFromFile string
+	userForce    bool
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage users",
+	Long: `
+DESCRIPTION
+
+  Create, update and delete users, lock them out or change their password.
+
+BULK MODE
+
+  The 'create' and 'update' sub-commands also accept a --from-file flag, pointing to a CSV or a JSON file
+  that defines one user per row. The result of each row is reported and the command fails if at least one row has failed.
+
+  CSV files must start with a header line, with some of the following columns:
+  login, password, displayName, email, groupPath, profile. For instance:
+
+    login,password,displayName,email,groupPath,profile
+    alice,Secr3t!,Alice Doe,alice@example.com,/engineering,standard
+
+  JSON files must contain an array of objects with the same keys:
+
+    [{"login": "alice", "password": "Secr3t!", "displayName": "Alice Doe", "groupPath": "/engineering"}]
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create new users",
+	Long: `
+DESCRIPTION
+
+  Create a new user with the passed login, or all the users that are defined in a file.
+  If no password is given with --user-password, it is prompted. The profile defaults to 'standard'.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm user create alice --display-name "Alice Doe" --email alice@example.com --group-path /engineering
+
+  ` + os.Args[0] + ` idm user create --from-file new-hires.csv
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if userFromFile != "" {
+			applyToUserDefinitions(userFromFile, func(def *rest.UserDefinition) error {
+				_, err := rest.CreateUser(def)
+				return err
+			})
+			return
+		}
+		userDef.Login = loginFromArgs(args)
+		if userDef.Password == "" {
+			userDef.Password = promptPassword()
+		}
+		u, err := rest.CreateUser(userDef)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("User %s has been created with ID %s\n", u.Login, u.UUID)
+	},
+}
+
+var userUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modify existing users",
+	Long: `
+DESCRIPTION
+
+  Modify the attributes of an existing user, or of all users that are defined in a file.
+  Only the attributes that are passed are changed. Changing the group path moves the user to another group.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm user update alice --email alice.doe@example.com --user-profile admin
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if userFromFile != "" {
+			applyToUserDefinitions(userFromFile, func(def *rest.UserDefinition) error {
+				_, err := rest.UpdateUser(def)
+				return err
+			})
+			return
+		}
+		userDef.Login = loginFromArgs(args)
+		if _, err := rest.UpdateUser(userDef); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("User %s has been updated\n", userDef.Login)
+	},
+}
+
+var userDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete users",
+	Long: `
+DESCRIPTION
+
+  Delete the users with the passed logins. Their personal files are handled as defined by the server configuration.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if !userForce {
+			p := promptui.Select{Label: fmt.Sprintf("Delete %s", strings.Join(args, ", ")), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+		reportPerUser(args, rest.DeleteUser, "deleted")
+	},
+}
+
+var userLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Prevent users from logging in",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		reportPerUser(args, func(login string) error { return rest.SetUserLocked(login, true) }, "locked")
+	},
+}
+
+var userUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Allow locked users to log in again",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		reportPerUser(args, func(login string) error { return rest.SetUserLocked(login, false) }, "unlocked")
+	},
+}
+
+var userSetPasswordCmd = &cobra.Command{
+	Use:   "set-password",
+	Short: "Change the password of a user",
+	Long: `
+DESCRIPTION
+
+  Change the password of a user. If the --user-password flag is not set, the new password is prompted.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		pwd := userDef.Password
+		if pwd == "" {
+			pwd = promptPassword()
+		}
+		if err := rest.SetUserPassword(args[0], pwd); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Password of %s has been changed\n", args[0])
+	},
+}
+
+func loginFromArgs(args []string) string {
+	if len(args) == 0 {
+		log.Fatal("Please provide a login or use the --from-file flag")
+	}
+	return args[0]
+}
+
+func promptPassword() string {
+	p := promptui.Prompt{Label: "Password", Mask: '*', Validate: notEmpty}
+	pwd, err := p.Run()
+	if err != nil {
+		log.Fatal("Aborted")
+	}
+	return pwd
+}
+
+// reportPerUser applies the passed function to each login and reports the result,
+// exiting with a non-zero status if at least one call has failed.
+func reportPerUser(logins []string, apply func(login string) error, done string) {
+	var failed int
+	for _, login := range logins {
+		if err := apply(login); err != nil {
+			failed++
+			fmt.Printf("%s %s: %s\n", promptui.IconBad, login, err.Error())
+			continue
+		}
+		fmt.Printf("%s %s has been %s\n", promptui.IconGood, login, done)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// applyToUserDefinitions reads the users defined in the passed file and applies the function to each row.
+func applyToUserDefinitions(file string, apply func(def *rest.UserDefinition) error) {
+	defs, err := readUserDefinitions(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var failed int
+	for i, def := range defs {
+		if err := apply(def); err != nil {
+			failed++
+			fmt.Printf("%s row %d (%s): %s\n", promptui.IconBad, i+1, def.Login, err.Error())
+			continue
+		}
+		fmt.Printf("%s row %d (%s)\n", promptui.IconGood, i+1, def.Login)
+	}
+	fmt.Printf("%d rows processed, %d failed\n", len(defs), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// readUserDefinitions parses a CSV or JSON file, depending on its extension.
+func readUserDefinitions(file string) ([]*rest.UserDefinition, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var defs []*rest.UserDefinition
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		if err = json.NewDecoder(f).Decode(&defs); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %s", file, err.Error())
+		}
+		return defs, nil
+	case ".csv":
+		r := csv.NewReader(f)
+		r.TrimLeadingSpace = true
+		header, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("could not read header of %s: %s", file, err.Error())
+		}
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("could not parse %s: %s", file, err.Error())
+			}
+			row := make(map[string]string, len(header))
+			for i, col := range header {
+				if i < len(record) {
+					row[strings.TrimSpace(col)] = strings.TrimSpace(record[i])
+				}
+			}
+			defs = append(defs, &rest.UserDefinition{
+				Login:       row["login"],
+				Password:    row["password"],
+				DisplayName: row["displayName"],
+				Email:       row["email"],
+				GroupPath:   row["groupPath"],
+				Profile:     row["profile"],
+			})
+		}
+		return defs, nil
+	}
+	return nil, fmt.Errorf("unsupported file %s, only .csv and .json files are supported", file)
+}
+
+// addUserFlags registers the flags that define a user. The password and profile flags are prefixed,
+// so that they do not shadow the global flags that are used to connect to the server.
+func addUserFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&userDef.Password, "user-password", "", "Password of the user")
+	flags.StringVar(&userDef.DisplayName, "display-name", "", "Display name of the user")
+	flags.StringVar(&userDef.Email, "email", "", "Email address of the user")
+	flags.StringVar(&userDef.GroupPath, "group-path", "", "Full path of the group of the user, e.g: /engineering")
+	flags.StringVar(&userDef.Profile, "user-profile", "", "Profile of the user, one of "+strings.Join(rest.UserProfiles, ", "))
+	flags.StringVar(&userFromFile, "from-file", "", "Read the users from a CSV or JSON file")
+}
+
 func init() {
+	addUserFlags(userCreateCmd.Flags())
+	addUserFlags(userUpdateCmd.Flags())
+	userSetPasswordCmd.Flags().StringVar(&userDef.Password, "user-password", "", "The new password, prompted if not set")
+	userDeleteCmd.Flags().BoolVarP(&userForce, "force", "f", false, "Do not ask for user approval")
+
+	userCmd.AddCommand(userCreateCmd)
+	userCmd.AddCommand(userUpdateCmd)
+	userCmd.AddCommand(userDeleteCmd)
+	userCmd.AddCommand(userLockCmd)
+	userCmd.AddCommand(userUnlockCmd)
+	userCmd.AddCommand(userSetPasswordCmd)
+	idmCmd.AddCommand(userCmd)
 	idmCmd.AddCommand(listUsers)
 }
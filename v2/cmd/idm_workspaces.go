@@ -3,8 +3,14 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strings"
 
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/pydio/cells-sdk-go/v3/client/workspace_service"
 	"github.com/pydio/cells-sdk-go/v3/models"
@@ -30,7 +36,7 @@ DESCRIPTION
 
 		//retrieves the users using the searchWorkspacesParams function
 		params := &workspace_service.SearchWorkspacesParams{
-			Body:    &models.RestSearchWorkspaceRequest{CountOnly: true},
+			Body:    &models.RestSearchWorkspaceRequest{},
 			Context: ctx,
 		}
 
@@ -41,17 +47,272 @@ DESCRIPTION
 			log.Fatal(err)
 		}
 
+		if printList(result.Payload.Workspaces) {
+			return
+		}
+
 		//prints the workspace label
 		if len(result.Payload.Workspaces) > 0 {
 			fmt.Printf("* %d workspace found\n", len(result.Payload.Workspaces))
 			for _, u := range result.Payload.Workspaces {
-				fmt.Println("  - " + u.Label)
+				fmt.Printf("  - %s (%s)\n", u.Label, u.Slug)
+			}
+		}
+
+	},
+}
+
+var (
+	wsSlug          string
+	wsLabel         string
+	wsDescription   string
+	wsRoots         []string
+	wsDefaultRights string
+	wsForce         bool
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage workspaces",
+	Long: `
+DESCRIPTION
+
+  Create, configure and delete workspaces. Workspaces are designated by their slug or their UUID.
+
+ROOTS
+
+  The roots of a workspace are either:
+   - the ID of a template path, e.g: my-files,
+   - or the path of a folder in a datasource, e.g: pydiods1/customers/acme.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a workspace",
+	Long: `
+DESCRIPTION
+
+  Create a new workspace. The --default-rights flag defines the rights that are granted to all users:
+  r, w, rw, or an empty string (the default) to only grant access through roles.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm workspace create --slug acme --label "ACME Corp" --root pydiods1/customers/acme --default-rights ""
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		if wsSlug == "" || wsLabel == "" {
+			log.Fatal("Please provide at least a slug and a label with the --slug and --label flags")
+		}
+		if len(wsRoots) == 0 {
+			log.Fatal("Please provide at least one root with the --root flag")
+		}
+		if _, err := rest.FindWorkspace(wsSlug); err == nil {
+			log.Fatalf("Workspace %s already exists", wsSlug)
+		}
+		ws := &models.IdmWorkspace{
+			Slug:        wsSlug,
+			Label:       wsLabel,
+			Description: wsDescription,
+			Scope:       models.NewIdmWorkspaceScope(models.IdmWorkspaceScopeADMIN),
+		}
+		rest.SetWorkspaceAttribute(ws, rest.WsAttrAllowSync, true)
+		if err := rest.SetWorkspaceRoots(ws, wsRoots); err != nil {
+			log.Fatal(err)
+		}
+		saved, err := rest.SaveWorkspace(ws, &wsDefaultRights)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Workspace %s has been created with ID %s\n", saved.Slug, saved.UUID)
+	},
+}
+
+var workspaceUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modify a workspace",
+	Long: `
+DESCRIPTION
+
+  Modify an existing workspace. Only the settings that are passed via flags are changed,
+  passing --root replaces all roots of the workspace: the rights that roles have on the old roots
+  are then moved to the new ones.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` idm workspace update acme --label "ACME Corporation" --default-rights r
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ws, err := rest.FindWorkspace(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		flags := cm.Flags()
+		if flags.Changed("label") {
+			ws.Label = wsLabel
+		}
+		if flags.Changed("description") {
+			ws.Description = wsDescription
+		}
+		oldRoots := append([]string{}, ws.RootUUIDs...)
+		if flags.Changed("root") {
+			if err = rest.SetWorkspaceRoots(ws, wsRoots); err != nil {
+				log.Fatal(err)
+			}
+		}
+		var rights *string
+		if flags.Changed("default-rights") {
+			rights = &wsDefaultRights
+		}
+		saved, err := rest.SaveWorkspace(ws, rights)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if flags.Changed("root") {
+			// Rights are granted on the root nodes: move them to the new roots
+			if err = rest.MoveWorkspaceAcls(saved, oldRoots); err != nil {
+				log.Fatalf("Workspace %s has been updated, but its rights could not be moved to the new roots: %s", ws.Slug, err.Error())
+			}
+		}
+		fmt.Printf("Workspace %s has been updated\n", ws.Slug)
+	},
+}
+
+var workspaceDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a workspace",
+	Long: `
+DESCRIPTION
+
+  Delete a workspace. The files and folders of its roots are left untouched in the datasources.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ws, err := rest.FindWorkspace(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !wsForce {
+			p := promptui.Select{Label: fmt.Sprintf("Delete workspace %s", ws.Slug), Items: []string{"No", "Yes"}}
+			if _, resp, e := p.Run(); resp != "Yes" || e != nil {
+				fmt.Println("Nothing will be deleted")
+				return
+			}
+		}
+		if err = rest.DeleteWorkspace(ws.Slug); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Workspace %s has been deleted\n", ws.Slug)
+	},
+}
+
+var workspaceShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration of a workspace",
+	Long: `
+DESCRIPTION
+
+  Show the roots and the attributes of a workspace, and the roles that hold rights on it.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ws, err := rest.FindWorkspace(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Workspace %s (%s)\n", ws.Label, ws.Slug)
+		fmt.Printf("  - UUID: %s\n", ws.UUID)
+		if ws.Description != "" {
+			fmt.Printf("  - Description: %s\n", ws.Description)
+		}
+		if ws.Scope != nil {
+			fmt.Printf("  - Scope: %s\n", *ws.Scope)
+		}
+
+		fmt.Println("\nRoots")
+		for id, n := range ws.RootNodes {
+			p := n.Path
+			if p == "" {
+				p = id
+			}
+			fmt.Printf("  - %s\n", p)
+		}
+
+		attrs := rest.WorkspaceAttributes(ws)
+		if len(attrs) > 0 {
+			fmt.Println("\nAttributes")
+			var keys []string
+			for k := range attrs {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("  - %s: %v\n", k, attrs[k])
+			}
+		}
+
+		acls, err := rest.ListWorkspaceAcls(ws.UUID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		roles, err := rest.ListRoles(true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		labels := make(map[string]string, len(roles))
+		for _, r := range roles {
+			labels[r.UUID] = r.Label
+		}
+		actions := map[string]map[string]bool{}
+		for _, acl := range acls {
+			if acl.Action == nil || acl.RoleID == "" {
+				continue
 			}
+			if actions[acl.RoleID] == nil {
+				actions[acl.RoleID] = map[string]bool{}
+			}
+			actions[acl.RoleID][acl.Action.Name] = true
 		}
 
+		fmt.Println("\nRoles")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Role", "Label", "Rights"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for roleID, set := range actions {
+			var names []string
+			for name := range set {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			table.Append([]string{roleID, labels[roleID], strings.Join(names, ", ")})
+		}
+		table.Render()
 	},
 }
 
+func addWorkspaceFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&wsLabel, "label", "", "Label of the workspace")
+	flags.StringVar(&wsDescription, "description", "", "Description of the workspace")
+	flags.StringArrayVar(&wsRoots, "root", []string{}, "Root of the workspace, either a template path ID or a datasource path, can be repeated")
+	flags.StringVar(&wsDefaultRights, "default-rights", "", "Rights granted to all users: r, w, rw or empty")
+}
+
 func init() {
+	workspaceCreateCmd.Flags().StringVar(&wsSlug, "slug", "", "Slug of the workspace, used in the paths")
+	addWorkspaceFlags(workspaceCreateCmd.Flags())
+	addWorkspaceFlags(workspaceUpdateCmd.Flags())
+	workspaceDeleteCmd.Flags().BoolVarP(&wsForce, "force", "f", false, "Do not ask for user approval")
+
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceUpdateCmd)
+	workspaceCmd.AddCommand(workspaceDeleteCmd)
+	workspaceCmd.AddCommand(workspaceShowCmd)
+	idmCmd.AddCommand(workspaceCmd)
 	idmCmd.AddCommand(listWorkspaces)
 }
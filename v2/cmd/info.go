@@ -1,27 +1,199 @@
 package cmd
 
 import (
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
+	"github.com/pydio/cells-client/v2/common"
 	"github.com/pydio/cells-client/v2/rest"
 )
 
+// infoReport gathers what is displayed by the info command.
+type infoReport struct {
+	User          string               `json:"user" yaml:"user"`
+	URL           string               `json:"url" yaml:"url"`
+	AuthType      string               `json:"authType" yaml:"authType"`
+	ClientVersion string               `json:"clientVersion" yaml:"clientVersion"`
+	Server        *infoServer          `json:"server,omitempty" yaml:"server,omitempty"`
+	Compatible    *bool                `json:"compatible,omitempty" yaml:"compatible,omitempty"`
+	Compatibility string               `json:"compatibility" yaml:"compatibility"`
+	Workspaces    []rest.UserWorkspace `json:"workspaces" yaml:"workspaces"`
+	GroupPath     string               `json:"groupPath,omitempty" yaml:"groupPath,omitempty"`
+	Groups        []string             `json:"groups" yaml:"groups"`
+	Roles         []string             `json:"roles" yaml:"roles"`
+	// Errors lists the information that could not be retrieved, e.g. because of missing permissions.
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+type infoServer struct {
+	Version     string    `json:"version" yaml:"version"`
+	Edition     string    `json:"edition" yaml:"edition"`
+	PackageType string    `json:"packageType" yaml:"packageType"`
+	License     string    `json:"license,omitempty" yaml:"license,omitempty"`
+	BuildStamp  time.Time `json:"buildStamp" yaml:"buildStamp"`
+}
+
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Display the active user, server and authentication type",
+	Long: `
+DESCRIPTION
+
+  Display information about the current connection: the user and the authentication type, the version and edition
+  of the server and whether it is supported by this client, the workspaces that are accessible with their permissions,
+  and the groups and roles of the user.
+
+  Use the --output flag to get this information as JSON or YAML, e.g. in scripts.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` info
+  ` + os.Args[0] + ` info -o json
+`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		dc := rest.DefaultConfig
+		report := collectInfo()
+		if done, err := printStructured(cmd.OutOrStdout(), report); done {
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
 
 		t := tablewriter.NewWriter(cmd.OutOrStdout())
 		t.SetHeader([]string{"Username", "URL", "Type"})
 		t.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-		t.Append([]string{dc.User, dc.Url, dc.AuthType})
+		t.Append([]string{report.User, report.URL, report.AuthType})
+		t.Render()
+
+		t = tablewriter.NewWriter(cmd.OutOrStdout())
+		t.SetHeader([]string{"Client", "Server", "Edition", "Build", "Compatibility"})
+		t.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		if s := report.Server; s != nil {
+			t.Append([]string{report.ClientVersion, s.Version, s.Edition, s.BuildStamp.Format("2006-01-02"), report.Compatibility})
+		} else {
+			t.Append([]string{report.ClientVersion, "unknown", "", "", report.Compatibility})
+		}
 		t.Render()
+
+		if len(report.Workspaces) > 0 {
+			t = tablewriter.NewWriter(cmd.OutOrStdout())
+			t.SetHeader([]string{"Workspace", "Slug", "Permissions"})
+			t.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			for _, ws := range report.Workspaces {
+				t.Append([]string{ws.Label, ws.Slug, aclLabel(ws.ACL)})
+			}
+			t.Render()
+		}
+
+		if report.GroupPath != "" || len(report.Groups) > 0 || len(report.Roles) > 0 {
+			t = tablewriter.NewWriter(cmd.OutOrStdout())
+			t.SetHeader([]string{"Group path", "Groups", "Roles"})
+			t.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			t.Append([]string{report.GroupPath, strings.Join(report.Groups, ", "), strings.Join(report.Roles, ", ")})
+			t.Render()
+		}
+
+		for _, e := range report.Errors {
+			cmd.PrintErrln("[WARNING]", e)
+		}
 	},
 }
 
+// collectInfo retrieves all the information of the info command. Failures are recorded in the report,
+// so that the information that is available is still displayed.
+func collectInfo() *infoReport {
+	dc := rest.DefaultConfig
+	report := &infoReport{
+		User:          dc.User,
+		URL:           dc.Url,
+		AuthType:      dc.AuthType,
+		ClientVersion: common.Version,
+		Workspaces:    []rest.UserWorkspace{},
+		Groups:        []string{},
+		Roles:         []string{},
+	}
+	if report.User == "" {
+		if login, err := rest.RetrieveCurrentSessionLogin(); err == nil {
+			report.User = login
+		}
+	}
+
+	if sv, err := rest.RetrieveRemoteServerVersion(); err != nil {
+		report.Compatibility = "unknown"
+		report.Errors = append(report.Errors, "could not retrieve server version: "+err.Error())
+	} else {
+		report.Server = &infoServer{
+			Version:     sv.Version,
+			Edition:     sv.PackageLabel,
+			PackageType: sv.PackageType,
+			License:     sv.License,
+			BuildStamp:  sv.BuildStamp,
+		}
+		var warning *rest.CheckWarning
+		switch err = rest.CheckServerCompatibility(sv); {
+		case err == nil:
+			compatible := true
+			report.Compatible = &compatible
+			report.Compatibility = "supported"
+		case errors.As(err, &warning):
+			report.Compatibility = "unknown, " + warning.Message
+		default:
+			compatible := false
+			report.Compatible = &compatible
+			report.Compatibility = "not supported, " + err.Error()
+		}
+	}
+
+	if ww, err := rest.RetrieveUserWorkspaces(); err != nil {
+		report.Errors = append(report.Errors, "could not list workspaces: "+err.Error())
+	} else {
+		sort.Slice(ww, func(i, j int) bool { return ww[i].Label < ww[j].Label })
+		report.Workspaces = ww
+	}
+
+	if report.User != "" {
+		if u, err := rest.FindUser(report.User); err != nil {
+			report.Errors = append(report.Errors, "could not retrieve groups and roles: "+err.Error())
+		} else {
+			report.GroupPath = u.GroupPath
+			for _, r := range u.Roles {
+				switch {
+				case r.UserRole:
+					// The personal role of the user
+				case r.GroupRole:
+					report.Groups = append(report.Groups, r.Label)
+				default:
+					report.Roles = append(report.Roles, r.Label)
+				}
+			}
+		}
+	}
+	return report
+}
+
+// aclLabel turns the ACL of a workspace in the registry into a readable label.
+func aclLabel(acl string) string {
+	var perms []string
+	if strings.Contains(acl, "r") {
+		perms = append(perms, "read")
+	}
+	if strings.Contains(acl, "w") {
+		perms = append(perms, "write")
+	}
+	if len(perms) == 0 {
+		return acl
+	}
+	return strings.Join(perms, ", ")
+}
+
 func init() {
 	RootCmd.AddCommand(infoCmd)
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var localOnly bool
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of a profile and revoke its tokens",
+	Long: `
+DESCRIPTION
+
+  Log out of the profile that is designated by its ID or its label, or of the active profile if none is passed.
+
+  For OAuth2 profiles, the access and refresh tokens are first revoked on the server, so that they cannot be used anymore
+  even if they have been copied. The sensitive information is then removed from the keyring and from the config file.
+  The profile itself is kept: use '` + os.Args[0] + ` configure' to log in again, or '` + os.Args[0] + ` config rm' to remove it.
+
+  Use the --local-only flag to skip the call to the server, e.g. if it cannot be reached anymore.
+  Beware that the tokens then remain valid on the server until they expire.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` logout admin@files.example.com
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		cl, err := rest.GetConfigList()
+		if err != nil {
+			log.Fatal(err)
+		}
+		id := cl.ActiveConfigID
+		if len(args) == 1 {
+			if id, err = cl.FindConfigID(args[0]); err != nil {
+				log.Fatal(err)
+			}
+		}
+		conf, ok := cl.Configs[id]
+		if !ok {
+			log.Fatal("No active profile found, please pass the profile to log out of")
+		}
+
+		if err = revokeProfile(conf); err != nil {
+			log.Fatal(err)
+		}
+		if !conf.SkipKeyring {
+			if err = rest.ClearKeyring(conf); err != nil {
+				log.Fatalf("could not clear keyring for %s: %s", id, err.Error())
+			}
+		}
+		err = rest.UpdateConfigList(func(list *rest.ConfigList) error {
+			stored, ok := list.Configs[id]
+			if !ok {
+				return fmt.Errorf("profile %s has been removed in the meantime", id)
+			}
+			stored.IdToken = ""
+			stored.RefreshToken = ""
+			stored.Password = ""
+			stored.TokenExpiresAt = 0
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s Logged out of %s\n", promptui.IconGood, id)
+	},
+}
+
+// revokeProfile revokes the tokens of the passed profile on the server, unless the --local-only flag is set.
+// The passed config is left untouched: sensitive information is retrieved from the keyring in a copy.
+func revokeProfile(conf *rest.CecConfig) error {
+	if localOnly {
+		return nil
+	}
+	c := *conf
+	if !c.SkipKeyring {
+		if err := rest.ConfigFromKeyring(&c); err != nil {
+			return fmt.Errorf("could not retrieve the tokens of %s from the keyring: %s", c.Label, err.Error())
+		}
+	}
+	if err := rest.OAuthRevokeTokens(&c); err != nil {
+		return fmt.Errorf("%s\nUse the --local-only flag to only remove local information", err.Error())
+	}
+	return nil
+}
+
+func init() {
+	logoutCmd.Flags().BoolVar(&localOnly, "local-only", false, "Do not revoke the tokens on the server, only remove local information")
+	RootCmd.AddCommand(logoutCmd)
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var metaFromStdin bool
+
+var metaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Manage user-defined metadata on files and folders",
+	Long: `
+DESCRIPTION
+
+  Read and write the user-defined metadata (tags, ratings, custom fields...) that are attached to the nodes of your server.
+  Metadata are organised in namespaces that are defined server side by an administrator:
+  use the 'namespaces' sub-command to discover the namespaces that are available.
+
+BULK MODE
+
+  The 'set' and 'delete' sub-commands can be applied to many nodes at once:
+   - either pass a path with wild chars (see the 'cp' command help for the supported syntax),
+   - or use the --stdin flag and provide the list of paths on the standard input, one per line.
+     In such case, the path argument must be omitted.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var metaNamespacesCmd = &cobra.Command{
+	Use:     "namespaces",
+	Aliases: []string{"ns"},
+	Short:   "List the metadata namespaces that are defined on the server",
+	Run: func(cm *cobra.Command, args []string) {
+		nss, err := rest.ListMetaNamespaces()
+		if err != nil {
+			log.Fatal(err)
+		}
+		sort.Slice(nss, func(i, j int) bool {
+			return nss[i].Order < nss[j].Order
+		})
+		if printList(nss) {
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Namespace", "Label", "Type", "Indexable"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, ns := range nss {
+			table.Append([]string{ns.Namespace, ns.Label, rest.MetaNamespaceType(ns), fmt.Sprintf("%v", ns.Indexable)})
+		}
+		table.Render()
+	},
+}
+
+var metaGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the metadata of a node",
+	Long: `
+DESCRIPTION
+
+  Show the value of a given namespace for the node at the passed path.
+  If no namespace is given, all user-defined metadata of the node are listed.
+
+EXAMPLES
+
+  # List all user-defined metadata of a file
+  ` + os.Args[0] + ` meta get common-files/report.pdf
+
+  # Only retrieve the value of the 'usermeta-tags' namespace
+  ` + os.Args[0] + ` meta get common-files/report.pdf usermeta-tags
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cm *cobra.Command, args []string) {
+		node, err := rest.GetNodeWithMeta(args[0])
+		if err != nil {
+			log.Fatalf("Could not retrieve metadata for %s: %s", args[0], err.Error())
+		}
+
+		if len(args) == 2 {
+			v, ok := node.MetaStore[args[1]]
+			if !ok {
+				log.Fatalf("No value found for %s on %s", args[1], args[0])
+			}
+			fmt.Println(displayMetaValue(v))
+			return
+		}
+
+		nss, err := rest.ListMetaNamespaces()
+		if err != nil {
+			log.Fatal(err)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Namespace", "Label", "Value"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, ns := range nss {
+			if v, ok := node.MetaStore[ns.Namespace]; ok {
+				table.Append([]string{ns.Namespace, ns.Label, displayMetaValue(v)})
+			}
+		}
+		table.Render()
+	},
+}
+
+var metaSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the value of a metadata on one or more nodes",
+	Long: `
+DESCRIPTION
+
+  Store a value for the given namespace on the node(s) found at the passed path.
+  If the value is not valid JSON, it is stored as a JSON string.
+
+EXAMPLES
+
+  # Set a custom field on a single file
+  ` + os.Args[0] + ` meta set common-files/report.pdf usermeta-reference "ACME-2023-12"
+
+  # Rate all the pictures of a folder
+  ` + os.Args[0] + ` meta set 'common-files/photos/*.jpg' usermeta-rating 5
+
+  # Apply the same value to a list of files
+  cat files.txt | ` + os.Args[0] + ` meta set --stdin usermeta-reference "ACME-2023-12"
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		pattern, args := splitBulkArgs(args, 2)
+		nodes, err := resolveBulkNodes(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ns, value := args[0], rest.ToJSONMetaValue(args[1])
+		applyToNodes(nodes, func(node *models.TreeNode) error {
+			return rest.SetNodeMeta(node.UUID, ns, value)
+		})
+	},
+}
+
+var metaDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Remove a metadata from one or more nodes",
+	Long: `
+DESCRIPTION
+
+  Remove the value of the given namespace from the node(s) found at the passed path.
+
+EXAMPLES
+
+  # Remove a custom field from a single file
+  ` + os.Args[0] + ` meta delete common-files/report.pdf usermeta-reference
+
+  # Remove the ratings of all pictures of a folder and its sub-folders
+  ` + os.Args[0] + ` meta delete 'common-files/photos/**/*.jpg' usermeta-rating
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		pattern, args := splitBulkArgs(args, 1)
+		nodes, err := resolveBulkNodes(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ns := args[0]
+		applyToNodes(nodes, func(node *models.TreeNode) error {
+			return rest.DeleteNodeMeta(node.UUID, ns)
+		})
+	},
+}
+
+// splitBulkArgs checks the number of passed arguments and separates the path (or pattern)
+// from the other arguments, taking the --stdin flag into account.
+func splitBulkArgs(args []string, expected int) (string, []string) {
+	if metaFromStdin {
+		if len(args) != expected {
+			log.Fatalf("Expected %d arguments when reading paths from standard input, got %d", expected, len(args))
+		}
+		return "", args
+	}
+	if len(args) != expected+1 {
+		log.Fatalf("Expected %d arguments, got %d", expected+1, len(args))
+	}
+	return args[0], args[1:]
+}
+
+// resolveBulkNodes retrieves the nodes that are targeted by a bulk command:
+// either read from the standard input, or found by expanding the passed pattern.
+func resolveBulkNodes(pattern string) ([]*models.TreeNode, error) {
+	var nodes []*models.TreeNode
+	if metaFromStdin {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			p := strings.Trim(strings.TrimSpace(scanner.Text()), "/")
+			if p == "" {
+				continue
+			}
+			n, ok := rest.StatNode(p)
+			if !ok {
+				return nil, fmt.Errorf("no node found at %s", p)
+			}
+			nodes = append(nodes, n)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("could not read paths from standard input: %s", err.Error())
+		}
+	} else {
+		var err error
+		if nodes, err = rest.ExpandGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no node found on the server matching %s", pattern)
+	}
+	return nodes, nil
+}
+
+// applyToNodes calls the passed function for each node, reports the result for each of them
+// and exits with a non-zero status if at least one call has failed.
+func applyToNodes(nodes []*models.TreeNode, apply func(node *models.TreeNode) error) {
+	var failed int
+	for _, n := range nodes {
+		p := strings.Trim(n.Path, "/")
+		if err := apply(n); err != nil {
+			failed++
+			fmt.Printf("%s %s: %s\n", promptui.IconBad, p, err.Error())
+		} else {
+			fmt.Printf("%s %s\n", promptui.IconGood, p)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d operations have failed\n", failed, len(nodes))
+		os.Exit(1)
+	}
+}
+
+// displayMetaValue removes the JSON quotes around simple string values.
+func displayMetaValue(v string) string {
+	var s string
+	if err := json.Unmarshal([]byte(v), &s); err == nil {
+		return s
+	}
+	return v
+}
+
+func init() {
+	metaSetCmd.Flags().BoolVar(&metaFromStdin, "stdin", false, "Read the list of target paths from the standard input, one per line")
+	metaDeleteCmd.Flags().BoolVar(&metaFromStdin, "stdin", false, "Read the list of target paths from the standard input, one per line")
+
+	metaCmd.AddCommand(metaNamespacesCmd)
+	metaCmd.AddCommand(metaGetCmd)
+	metaCmd.AddCommand(metaSetCmd)
+	metaCmd.AddCommand(metaDeleteCmd)
+	RootCmd.AddCommand(metaCmd)
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var tagNamespace string
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage the tags of files and folders",
+	Long: `
+DESCRIPTION
+
+  Add, remove and list the tags of the nodes of your server.
+  Tags are stored in a user meta namespace of type 'tags'. If only one such namespace is defined on your server,
+  it is used by default, otherwise specify the namespace to use with the --namespace flag.
+
+  As with the 'meta' command, the 'add' and 'rm' sub-commands support wild chars in the path
+  or reading the list of target paths from the standard input with the --stdin flag.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add one or more tags to the nodes",
+	Long: `
+DESCRIPTION
+
+  Add the passed tags to the node(s) found at the given path, keeping the tags that are already present.
+
+EXAMPLES
+
+  # Tag a single file
+  ` + os.Args[0] + ` tag add common-files/report.pdf finance 2023
+
+  # Tag all PDF files of a folder
+  ` + os.Args[0] + ` tag add 'common-files/reports/*.pdf' finance
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		ns := tagNamespaceOrDefault()
+		pattern, tags := splitTagArgs(args)
+		nodes, err := resolveBulkNodes(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Also register the new tags so that they are proposed in the web UI
+		for _, t := range tags {
+			if err = rest.RegisterMetaTag(ns, t); err != nil {
+				fmt.Println(err.Error())
+			}
+		}
+		applyToNodes(nodes, func(node *models.TreeNode) error {
+			current, err := nodeTags(node, ns)
+			if err != nil {
+				return err
+			}
+			return rest.SetNodeMeta(node.UUID, ns, rest.EncodeTags(mergeTags(current, tags)))
+		})
+	},
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:   "rm",
+	Short: "Remove one or more tags from the nodes",
+	Long: `
+DESCRIPTION
+
+  Remove the passed tags from the node(s) found at the given path.
+
+EXAMPLES
+
+  # Remove a tag from all files of a folder and its sub-folders
+  ` + os.Args[0] + ` tag rm 'common-files/reports/**' draft
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		ns := tagNamespaceOrDefault()
+		pattern, tags := splitTagArgs(args)
+		nodes, err := resolveBulkNodes(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		applyToNodes(nodes, func(node *models.TreeNode) error {
+			current, err := nodeTags(node, ns)
+			if err != nil {
+				return err
+			}
+			remaining := removeTags(current, tags)
+			if len(remaining) == 0 {
+				return rest.DeleteNodeMeta(node.UUID, ns)
+			}
+			return rest.SetNodeMeta(node.UUID, ns, rest.EncodeTags(remaining))
+		})
+	},
+}
+
+var tagLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the tags of a node or all known tags",
+	Long: `
+DESCRIPTION
+
+  Without argument, list all the tags that have already been used in the namespace.
+  Otherwise, list the tags of the node found at the passed path.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		ns := tagNamespaceOrDefault()
+		var tags []string
+		var err error
+		if len(args) == 0 {
+			tags, err = rest.ListMetaTags(ns)
+		} else {
+			var node *models.TreeNode
+			if node, err = rest.GetNodeWithMeta(args[0]); err == nil {
+				tags = rest.DecodeTags(node.MetaStore[ns])
+			}
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if printList(tags) {
+			return
+		}
+		for _, t := range tags {
+			fmt.Println(t)
+		}
+	},
+}
+
+// tagNamespaceOrDefault returns the namespace passed via the flag or the single tags namespace defined on the server.
+func tagNamespaceOrDefault() string {
+	if tagNamespace != "" {
+		return tagNamespace
+	}
+	nss, err := rest.ListMetaNamespaces()
+	if err != nil {
+		log.Fatal(err)
+	}
+	var found []string
+	for _, ns := range nss {
+		if rest.MetaNamespaceType(ns) == rest.MetaTypeTags {
+			found = append(found, ns.Namespace)
+		}
+	}
+	switch len(found) {
+	case 0:
+		log.Fatal("No namespace of type 'tags' is defined on the server")
+	case 1:
+		return found[0]
+	}
+	log.Fatalf("Several tags namespaces are defined on the server (%s), please choose one with the --namespace flag", strings.Join(found, ", "))
+	return ""
+}
+
+func splitTagArgs(args []string) (string, []string) {
+	if metaFromStdin {
+		if len(args) == 0 {
+			log.Fatal("Please provide at least one tag")
+		}
+		return "", args
+	}
+	if len(args) < 2 {
+		log.Fatal("Please provide a path and at least one tag")
+	}
+	return args[0], args[1:]
+}
+
+// nodeTags retrieves the current tags of the node: nodes found by the listing do not always carry the user metadata.
+func nodeTags(node *models.TreeNode, ns string) ([]string, error) {
+	withMeta, err := rest.GetNodeWithMeta(strings.Trim(node.Path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return rest.DecodeTags(withMeta.MetaStore[ns]), nil
+}
+
+func mergeTags(current, added []string) []string {
+	merged := current
+	for _, t := range added {
+		if !containsTag(merged, t) {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+func removeTags(current, removed []string) []string {
+	var remaining []string
+	for _, t := range current {
+		if !containsTag(removed, t) {
+			remaining = append(remaining, t)
+		}
+	}
+	return remaining
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	tagCmd.PersistentFlags().StringVarP(&tagNamespace, "namespace", "n", "", "The tags namespace to use, only required if several are defined on the server")
+	tagAddCmd.Flags().BoolVar(&metaFromStdin, "stdin", false, "Read the list of target paths from the standard input, one per line")
+	tagRmCmd.Flags().BoolVar(&metaFromStdin, "stdin", false, "Read the list of target paths from the standard input, one per line")
+
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRmCmd)
+	tagCmd.AddCommand(tagLsCmd)
+	RootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats that are supported by the --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+// printStructured prints v in the format that is selected with the --output flag.
+// It returns false when the table format is selected: the caller must then render its own tables.
+func printStructured(w io.Writer, v interface{}) (bool, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		// An empty list rather than null
+		v = reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+	switch outputFormat {
+	case "", outputTable:
+		return false, nil
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return true, enc.Encode(v)
+	case outputYAML:
+		// Go through JSON, so that the models of the SDK, that only have JSON tags, use the same field names in both formats
+		data, err := json.Marshal(v)
+		if err != nil {
+			return true, err
+		}
+		var doc yaml.Node
+		if err = yaml.Unmarshal(data, &doc); err != nil {
+			return true, err
+		}
+		blockStyle(&doc)
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return true, enc.Encode(&doc)
+	}
+	return true, fmt.Errorf("unknown output format %s, expected %s, %s or %s", outputFormat, outputTable, outputJSON, outputYAML)
+}
+
+// printList prints the result of a list command in the format that is selected with the --output flag, and exits on error.
+// It returns false when the table format is selected.
+func printList(v interface{}) bool {
+	done, err := printStructured(os.Stdout, v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return done
+}
+
+// blockStyle removes the JSON flow style and quotes of a parsed document, that are then only used where YAML requires them.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -27,7 +27,7 @@ const (
 
 var (
 	// These commands and respective children do not need an already configured environment.
-	infoCommands = []string{"help", "configure", "version", "completion", "oauth", "clear", "doc", "update", "token", "--help", "config"}
+	infoCommands = []string{"help", "configure", "version", "completion", "oauth", "clear", "doc", "update", "token", "--help", "config", "logout", "auth", "doctor"}
 
 	configFilePath string
 
@@ -36,10 +36,16 @@ var (
 	authType  string
 	login     string
 	password  string
+	profile   string
 
 	skipKeyring bool
 	skipVerify  bool
 	noCache     bool
+
+	caFile     string
+	clientCert string
+	clientKey  string
+	proxyURL   string
 )
 
 // RootCmd is the parent of all commands defined in this package.
@@ -68,6 +74,17 @@ CONFIGURE
 
   [Note]: if no keyring is found, all information are stored in clear text in the ` + confFileName + ` file, including sensitive bits.
 
+SECRETS
+
+  Use the --secret_backend flag (or the CEC_SECRET_BACKEND variable) to choose where the credentials of new profiles are stored:
+   - keyring: the keyring of the operating system (the default),
+   - file: a file next to the config file, encrypted with a passphrase that is read from CEC_SECRET_KEY or prompted,
+   - command: an external command that is defined with --secret_command, e.g: pass, vault or your own helper
+     that supports the 'get <name>', 'set <name>' (secret on stdin) and 'delete <name>' arguments,
+     and that exits with status 2 when 'get' is called for a secret that does not exist.
+     The command is stored with the profile, so that it does not need to be passed again.
+  When no keyring is available and CEC_SECRET_KEY is set, the encrypted file is used automatically.
+
 ENVIRONMENT
 
   All the command flags documented below are mapped to their associated ENV var, using upper case and CEC_ prefix.
@@ -81,6 +98,19 @@ ENVIRONMENT
     $ export CEC_URL=https://files.example.com; export CEC_TOKEN=<Your Personal Access Token>; 
     $ ` + os.Args[0] + ` ls
 
+NETWORK
+
+  If your server uses certificates that are signed by an internal CA, or requires a client certificate, or is only
+  reachable through a proxy, use the --ca_file, --client_cert and --client_key, and --proxy_url flags when configuring
+  the profile: they are stored with it. Use '` + os.Args[0] + ` config set' to change them afterwards.
+
+PROFILES
+
+  Several authentication profiles can be stored, see '` + os.Args[0] + ` config --help'.
+  By default, the active profile is used. Use the --profile flag (or the CEC_PROFILE variable) to use another one
+  for a single invocation, without changing the active profile: this is safe in scripts that run in parallel.
+    $ ` + os.Args[0] + ` ls --profile admin@files.example.com
+
 `,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 
@@ -117,9 +147,18 @@ ENVIRONMENT
 		token = viper.GetString("token")
 		login = viper.GetString("login")
 		password = viper.GetString("password")
+		profile = viper.GetString("profile")
 		noCache = viper.GetBool("no_cache")
 		skipKeyring = viper.GetBool("skip_keyring")
 		skipVerify = viper.GetBool("skip_verify")
+		caFile = viper.GetString("ca_file")
+		clientCert = viper.GetString("client_cert")
+		clientKey = viper.GetString("client_key")
+		proxyURL = viper.GetString("proxy_url")
+		rest.SecretBackend = viper.GetString("secret_backend")
+		rest.SecretCommand = viper.GetString("secret_command")
+		rest.RefreshMargin = viper.GetDuration("refresh_margin")
+		outputFormat = viper.GetString("output")
 
 		if needSetup {
 			e := setUpEnvironment()
@@ -151,10 +190,19 @@ func init() {
 	flags.StringP("token", "t", "", "A valid Personal Access Token")
 	flags.String("login", "", "The user login, for Client auth only")
 	flags.String("password", "", "The user password, for Client auth only")
+	flags.String("profile", "", "ID or label of a stored profile to use for this invocation, instead of the active one")
 
 	flags.Bool("skip_verify", false, "By default the Cells Client verifies the validity of TLS certificates for each communication. This option skips TLS certificate verification")
 	flags.Bool("skip_keyring", false, "Explicitly tell the tool to *NOT* try to use a keyring, even if present. Warning: sensitive information will be stored in clear text")
+	flags.String("ca_file", "", "Path to a PEM bundle of certificate authorities to trust, in addition to the system ones, e.g. for an internal CA")
+	flags.String("client_cert", "", "Path to the PEM client certificate to present when the server requires mutual TLS")
+	flags.String("client_key", "", "Path to the PEM private key of the client certificate")
+	flags.String("proxy_url", "", "URL of the HTTP proxy to use to reach the server. By default, HTTPS_PROXY and HTTP_PROXY are used")
+	flags.StringP("output", "o", outputTable, "Output format of the list commands, info and idm export: table, json or yaml")
+	flags.Duration("refresh_margin", rest.DefaultRefreshMargin, "Refresh OAuth2 tokens when they expire within this delay, e.g: 30s or 5m")
 	flags.Bool("no_cache", false, "Force token refresh at each call. This might slow down scripts with many calls")
+	flags.String("secret_backend", "", "Where to store the credentials of new profiles: keyring, file or command")
+	flags.String("secret_command", "", "External command used by the command secret backend, e.g: pass or vault")
 
 	// Unused for the time being
 	// flags.StringP("auth_type", "a", "", "Authorization mechanism used: Personnal Access Token (Default), OAuth2 flow or Client Credentials")
@@ -166,7 +214,38 @@ func init() {
 // SetUpEnvironment configures the current runtime by setting the SDK Config that is used by child commands.
 // It first tries to retrieve parameters via flags or environment variables. If it is not enough to define a valid connection,
 // we check for a locally defined configuration file (that might also relies on local keyring to store sensitive info).
+// A profile that is explicitly selected with the --profile flag takes precedence over both.
 func setUpEnvironment() error {
+	c, err := resolveConfig()
+	if err != nil {
+		return err
+	}
+
+	// Refresh token if required
+	if _, err = rest.RefreshAndStoreIfRequired(&c); err != nil {
+		log.Fatal(err)
+	}
+
+	// Store current computed config in a public static singleton
+	rest.DefaultConfig = &c
+
+	return nil
+}
+
+// resolveConfig retrieves the config that is defined by the flags and ENV vars, or the selected stored profile,
+// with its sensitive information, but without refreshing its token.
+func resolveConfig() (rest.CecConfig, error) {
+	c, stored, err := resolveConfigWithoutSecrets()
+	if err != nil || !stored || c.SkipKeyring {
+		return c, err
+	}
+	err = rest.ConfigFromKeyring(&c)
+	return c, err
+}
+
+// resolveConfigWithoutSecrets works like resolveConfig, but does not load the sensitive information of stored profiles
+// from the keyring. It also tells if the config is a stored profile: otherwise it is defined by the flags and ENV vars.
+func resolveConfigWithoutSecrets() (rest.CecConfig, bool, error) {
 
 	if configFilePath != "" { // override default location for the configuration file
 		rest.SetConfigFilePath(configFilePath)
@@ -175,40 +254,35 @@ func setUpEnvironment() error {
 	// First Check if an environment is defined via the context (flags or ENV vars)
 	c := getCecConfigFromEnv()
 
-	if c.Url == "" {
+	if c.Url == "" || profile != "" {
 
 		// First check that we have a configuration file
 		_, err := ioutil.ReadFile(configFilePath)
 		if err != nil {
-			return err
+			return c, false, err
 		}
 
 		cl, err := rest.GetConfigList()
 		if err != nil {
-			return err
-		}
-
-		activeConfig, err := cl.GetActiveConfig()
-		if err != nil {
-			return err
+			return c, false, err
 		}
-		c = *activeConfig
 
-		// Refresh token if required
-		if refreshed, err := rest.RefreshIfRequired(&c); refreshed {
-			if err != nil {
-				log.Fatal("Could not refresh authentication token:", err)
+		id := cl.ActiveConfigID
+		if profile != "" {
+			if id, err = cl.FindConfigID(profile); err != nil {
+				log.Fatal(err)
 			}
-			// Copy config as IdToken will be cleared
-			storeConfig := c
-			rest.UpdateConfig(&storeConfig)
 		}
+		conf, err := cl.GetStoredConfig(id)
+		if err != nil {
+			return c, false, err
+		}
+		c = *conf
+		applyTransportFlags(&c)
+		return c, true, nil
 	}
 
-	// Store current computed config in a public static singleton
-	rest.DefaultConfig = &c
-
-	return nil
+	return c, false, nil
 }
 
 // getCecConfigFromEnv first check if a valid connection has been configured with flags and/or ENV var
@@ -247,10 +321,27 @@ func getCecConfigFromEnv() rest.CecConfig {
 	c.SkipVerify = skipVerify
 	c.SkipKeyring = skipKeyring
 	c.UseTokenCache = !noCache
+	applyTransportFlags(c)
 
 	return *c
 }
 
+// applyTransportFlags overrides the TLS and proxy settings of the config with the ones that are explicitly passed.
+func applyTransportFlags(c *rest.CecConfig) {
+	if caFile != "" {
+		c.CAFile = caFile
+	}
+	if clientCert != "" {
+		c.ClientCertFile = clientCert
+	}
+	if clientKey != "" {
+		c.ClientKeyFile = clientKey
+	}
+	if proxyURL != "" {
+		c.ProxyURL = proxyURL
+	}
+}
+
 // handleLegagyParams manages backward compatibility for ENV variables and flags.
 func handleLegagyParams() {
 
@@ -48,6 +48,10 @@ DESCRIPTION
 			log.Fatalf("Could not list data sources of %s, cause: %s", rest.DefaultConfig.Url, err.Error())
 		}
 
+		if !ldRaw && printList(result.Payload.DataSources) {
+			return
+		}
+
 		//prints the name of the datasources retrieved previously
 		if len(result.Payload.DataSources) > 0 {
 			if ldRaw {
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage authentication tokens",
+	Long: `
+DESCRIPTION
+
+  Manage the tokens that are used to authenticate against your Cells server.
+
+  Personal access tokens are generated for the user of the current profile. To configure a connection that uses
+  a personal access token, pass it to '` + os.Args[0] + ` config add token', or use the --generate flag of this command
+  to generate a dedicated token while you are logged in with OAuth2.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var (
+	tokenLabel   string
+	tokenExpires string
+	tokenScopes  []string
+)
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Generate a personal access token",
+	Long: `
+DESCRIPTION
+
+  Generate a personal access token for the current user. The token is printed on the standard output:
+  it cannot be retrieved afterwards, so store it in a safe place.
+
+  The expiration is either a date (YYYY-MM-DD) or a duration from now, e.g. 12h or 30d: without it, the token
+  does not expire. Scopes restrict what the token can be used for, the token otherwise grants the same rights as the user.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` token create --label ci --expires 30d
+  ` + os.Args[0] + ` token create --label backup --expires 2024-12-31 --scope rest:read
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		expiresAt, err := parseTokenExpiry(tokenExpires)
+		if err != nil {
+			log.Fatal(err)
+		}
+		requireEnvironment()
+		value, err := rest.GeneratePersonalToken(tokenLabel, expiresAt, tokenScopes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, _ = fmt.Fprintln(os.Stderr, "Token has been generated, it cannot be retrieved afterwards:")
+		fmt.Println(value)
+	},
+}
+
+var tokenLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your personal access tokens",
+	Long: `
+DESCRIPTION
+
+  List the personal access tokens of the current user. The values of the tokens are never listed.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cm *cobra.Command, args []string) {
+		requireEnvironment()
+		tokens, err := rest.ListPersonalTokens()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if printList(tokens) {
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Uuid", "Label", "Scopes", "Expires", "Created"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		for _, t := range tokens {
+			expires := "Never"
+			if e := t.ExpiresAt.Time(); !e.IsZero() {
+				expires = e.Format("2006-01-02 15:04")
+			}
+			created := ""
+			if c := t.CreatedAt.Time(); !c.IsZero() {
+				created = c.Format("2006-01-02 15:04")
+			}
+			table.Append([]string{t.UUID, t.Label, strings.Join(t.Scopes, ", "), expires, created})
+		}
+		fmt.Printf("Found %d tokens\n", len(tokens))
+		table.Render()
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke a token",
+	Long: `
+DESCRIPTION
+
+  Revoke a token on the server: it cannot be used anymore to authenticate, and the URLs that
+  have been signed with it stop working. The token is read from the standard input if it is not passed as argument,
+  so that it does not end up in your shell history.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` token revoke < leaked-token.txt
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		var token string
+		if len(args) > 0 {
+			token = args[0]
+		} else {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				log.Fatal("Please provide the token to revoke as argument or on the standard input")
+			}
+			token = strings.TrimSpace(line)
+		}
+		requireEnvironment()
+		if err := rest.RevokeToken(token); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Token has been revoked")
+	},
+}
+
+// requireEnvironment sets up the connection to the server: the token command does not require a configured environment,
+// but its sub-commands do.
+func requireEnvironment() {
+	if err := setUpEnvironment(); err != nil {
+		if os.IsNotExist(err) {
+			log.Fatalf("No configuration has been found, please make sure to run '%s configure' first.\n", os.Args[0])
+		}
+		log.Fatal(err)
+	}
+}
+
+// parseTokenExpiry returns the zero time if no expiration is passed.
+func parseTokenExpiry(s string) (time.Time, error) {
+	if s == "" || s == "0" || s == "none" {
+		return time.Time{}, nil
+	}
+	return parseExpiry(s)
+}
+
+// addTokenFlags adds the flags that describe a personal access token to generate.
+func addTokenFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&tokenLabel, "label", "", "Label of the token, to recognize it when listing tokens")
+	flags.StringVar(&tokenExpires, "expires", "", "Expiration date (YYYY-MM-DD) or duration from now, e.g. 12h or 30d. By default, the token does not expire")
+	flags.StringSliceVar(&tokenScopes, "scope", nil, "Restrict the token to this scope, can be repeated")
+}
+
+func init() {
+	addTokenFlags(tokenCreateCmd.Flags())
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenLsCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	RootCmd.AddCommand(tokenCmd)
+}
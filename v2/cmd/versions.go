@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/pydio/cells-client/v2/rest"
+)
+
+var (
+	versionID  string
+	versionRaw bool
+)
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "Manage the versions of your files",
+	Long: `
+DESCRIPTION
+
+  List, download and restore the previous versions of the files that are stored in a versioned datasource.
+  See the help of respective sub-commands for further details.
+`,
+	Run: func(cm *cobra.Command, args []string) {
+		cm.Usage()
+	},
+}
+
+var versionsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the versions of a file",
+	Long: `
+DESCRIPTION
+
+  List the known versions of the file at the passed path, most recent first.
+  The description of each version usually tells who created it.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` versions ls common-files/report.docx
+
+  # Only list the version IDs
+  ` + os.Args[0] + ` versions ls common-files/report.docx --raw
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		versions, err := rest.ListVersions(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !versionRaw && printList(versions) {
+			return
+		}
+		if len(versions) == 0 {
+			fmt.Printf("No version found for %s, is it stored in a versioned datasource?\n", args[0])
+			return
+		}
+
+		if versionRaw {
+			for _, v := range versions {
+				fmt.Println(v.ID)
+			}
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Version ID", "Date", "Size", "Description"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		for _, v := range versions {
+			table.Append([]string{v.ID, stampToDateTime(v.MTime), sizeToBytes(v.Size), v.Description})
+		}
+		fmt.Printf("Found %d versions for %s\n", len(versions), args[0])
+		table.Render()
+	},
+}
+
+var versionsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Download a given version of a file",
+	Long: `
+DESCRIPTION
+
+  Download a given version of a remote file to the client machine.
+  If no local target is given, the file is downloaded in the current folder with its original name.
+  As with the 'scp' command, if the target does not exist but its parent folder does,
+  the downloaded file is renamed.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` versions get common-files/report.docx --version 3f5a7c46-7d4e-4f7d-9d0b-9a8b0dd5a6e1 ./report-v1.docx
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cm *cobra.Command, args []string) {
+		if versionID == "" {
+			log.Fatal("Please provide the ID of the version to download with the --version flag")
+		}
+		remotePath := strings.Trim(args[0], "/")
+		to := "."
+		if len(args) > 1 {
+			to = args[1]
+		}
+
+		v, err := rest.FindVersion(remotePath, versionID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		targetPath, rename, err := localTargetPath(to)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		crawler, err := rest.NewCrawler(remotePath, false)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if crawler.IsDir {
+			log.Fatalf("%s is a folder, only files have versions", remotePath)
+		}
+		crawler.VersionID = v.ID
+		crawler.Size, _ = strconv.ParseInt(v.Size, 10, 64)
+
+		fmt.Printf("Downloading version %s of %s to %s\n", v.ID, remotePath, to)
+		transfer(crawler, targetPath, rename)
+		fmt.Println("") // Add a line to reduce glitches in the terminal
+	},
+}
+
+var versionsRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Promote an old version of a file as its current version",
+	Long: `
+DESCRIPTION
+
+  Restore a previous version of a file: its content is uploaded again to the same location,
+  so that it becomes the current version. The version history is kept intact,
+  a new version is simply added on top of it.
+
+EXAMPLES
+
+  ` + os.Args[0] + ` versions restore common-files/report.docx --version 3f5a7c46-7d4e-4f7d-9d0b-9a8b0dd5a6e1
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cm *cobra.Command, args []string) {
+		if versionID == "" {
+			log.Fatal("Please provide the ID of the version to restore with the --version flag")
+		}
+		remotePath := strings.Trim(args[0], "/")
+		if _, err := rest.FindVersion(remotePath, versionID); err != nil {
+			log.Fatal(err)
+		}
+		if err := rest.RestoreVersion(remotePath, versionID); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Version %s is now the current version of %s\n", versionID, path.Base(remotePath))
+	},
+}
+
+func stampToDateTime(stamp string) string {
+	if i, e := strconv.ParseInt(stamp, 10, 64); e == nil && i > 0 {
+		return time.Unix(i, 0).Format("2006-01-02 15:04:05")
+	}
+	return "-"
+}
+
+func init() {
+	versionsLsCmd.Flags().BoolVarP(&versionRaw, "raw", "r", false, "Only list the version IDs, one per line")
+	versionsGetCmd.Flags().StringVarP(&versionID, "version", "v", "", "ID of the version to download")
+	versionsRestoreCmd.Flags().StringVarP(&versionID, "version", "v", "", "ID of the version to restore")
+
+	versionsCmd.AddCommand(versionsLsCmd)
+	versionsCmd.AddCommand(versionsGetCmd)
+	versionsCmd.AddCommand(versionsRestoreCmd)
+	RootCmd.AddCommand(versionsCmd)
+}
@@ -0,0 +1,222 @@
+package rest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/acl_service"
+	"github.com/pydio/cells-sdk-go/v3/client/admin_tree_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// AccessLevel is a node on the way from the top of the tree to the node whose access is audited.
+type AccessLevel struct {
+	NodeID string
+	Path   string
+}
+
+// AccessReport gathers the ACLs that apply to a node, on the node itself, its ancestors and the roots of its workspace.
+type AccessReport struct {
+	Workspace *models.IdmWorkspace
+	// Levels are ordered from the top of the tree down to the audited node.
+	Levels []AccessLevel
+	ACLs   []*models.IdmACL
+}
+
+// Rights are the effective rights resolved from the ACLs of a list of roles.
+type Rights struct {
+	Read  bool
+	Write bool
+	Deny  bool
+	// DefinedOn is the path of the deepest node that defines the rights.
+	DefinedOn string
+}
+
+// CollectAccess resolves the passed path and retrieves all the ACLs that may apply to it.
+func CollectAccess(nodePath string) (*AccessReport, error) {
+	nodePath = strings.Trim(nodePath, "/")
+	parts := splitPath(nodePath)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("please provide a path inside a workspace")
+	}
+	ws, err := FindWorkspace(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := StatNode(nodePath); !ok {
+		return nil, fmt.Errorf("no node found at %s", nodePath)
+	}
+
+	report := &AccessReport{Workspace: ws}
+
+	// Find the root of the workspace that contains the node: when the workspace has several roots,
+	// they appear as folders at the top of the workspace.
+	var root *models.TreeNode
+	prefix := parts[:1]
+	for id, r := range ws.RootNodes {
+		r := r
+		if r.UUID == "" {
+			r.UUID = id
+		}
+		if len(ws.RootNodes) == 1 {
+			root = &r
+			break
+		}
+		if len(parts) > 1 && path.Base(r.Path) == parts[1] {
+			root = &r
+			prefix = parts[:2]
+			break
+		}
+	}
+
+	if root != nil {
+		// Ancestors of the root in the admin tree: only visible to administrators
+		for _, p := range ancestors(root.Path) {
+			if n, e := statAdminNode(p); e == nil {
+				report.Levels = append(report.Levels, AccessLevel{NodeID: n.UUID, Path: p})
+			}
+		}
+		report.Levels = append(report.Levels, AccessLevel{NodeID: root.UUID, Path: strings.Join(prefix, "/")})
+	}
+
+	// Then all folders from the root down to the node, as seen in the workspace
+	for i := len(prefix) + 1; i <= len(parts); i++ {
+		current := strings.Join(parts[:i], "/")
+		n, ok := StatNode(current)
+		if !ok {
+			return nil, fmt.Errorf("could not stat %s", current)
+		}
+		report.Levels = append(report.Levels, AccessLevel{NodeID: n.UUID, Path: current})
+	}
+
+	if len(report.Levels) == 0 {
+		return nil, fmt.Errorf("could not find the root of %s in workspace %s", nodePath, ws.Slug)
+	}
+
+	var nodeIDs []string
+	for _, l := range report.Levels {
+		nodeIDs = append(nodeIDs, l.NodeID)
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &acl_service.SearchAclsParams{
+		Body: &models.RestSearchACLRequest{
+			Queries: []*models.IdmACLSingleQuery{{
+				NodeIDs: nodeIDs,
+				Actions: []*models.IdmACLAction{{Name: AclRead}, {Name: AclWrite}, {Name: AclDeny}},
+			}},
+		},
+		Context: ctx,
+	}
+	res, err := client.ACLService.SearchAcls(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list ACLs: %s", err.Error())
+	}
+	for _, acl := range res.Payload.ACLs {
+		// Only keep the ACLs that apply when accessing the node through this workspace
+		if acl.WorkspaceID == "" || acl.WorkspaceID == ws.UUID {
+			report.ACLs = append(report.ACLs, acl)
+		}
+	}
+	return report, nil
+}
+
+// RoleIDs lists the roles that hold at least one ACL in the report.
+func (r *AccessReport) RoleIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, acl := range r.ACLs {
+		if !seen[acl.RoleID] {
+			seen[acl.RoleID] = true
+			ids = append(ids, acl.RoleID)
+		}
+	}
+	return ids
+}
+
+// Resolve computes the effective rights for the passed ordered list of roles.
+// This mimics the resolution of the server: for each node, the last role of the list
+// that defines rights wins, rights defined on deeper nodes override the rights of their ancestors,
+// and a deny on any level blocks the access.
+func (r *AccessReport) Resolve(roleIDs []string) Rights {
+	var rights Rights
+	for _, level := range r.Levels {
+		var levelRights *Rights
+		for _, roleID := range roleIDs {
+			var found bool
+			lr := Rights{DefinedOn: level.Path}
+			for _, acl := range r.ACLs {
+				if acl.NodeID != level.NodeID || acl.RoleID != roleID || acl.Action == nil {
+					continue
+				}
+				found = true
+				switch acl.Action.Name {
+				case AclRead:
+					lr.Read = true
+				case AclWrite:
+					lr.Write = true
+				case AclDeny:
+					lr.Deny = true
+				}
+			}
+			if found {
+				levelRights = &lr
+			}
+		}
+		if levelRights == nil {
+			continue
+		}
+		if levelRights.Deny {
+			return Rights{Deny: true, DefinedOn: level.Path}
+		}
+		rights = *levelRights
+	}
+	return rights
+}
+
+// UserRoleIDs returns the ordered list of the roles of a user or a group, as they are applied by the server.
+func UserRoleIDs(u *models.IdmUser) []string {
+	ids := []string{RootGroupRole}
+	for _, r := range u.Roles {
+		if r.UUID != RootGroupRole {
+			ids = append(ids, r.UUID)
+		}
+	}
+	// Make sure the personal role of the user comes last
+	if ids[len(ids)-1] != u.UUID {
+		ids = append(ids, u.UUID)
+	}
+	return ids
+}
+
+// ancestors lists the parent folders of the passed path, from the top of the tree.
+func ancestors(p string) []string {
+	parts := splitPath(p)
+	var list []string
+	for i := 1; i < len(parts); i++ {
+		list = append(list, strings.Join(parts[:i], "/"))
+	}
+	return list
+}
+
+func statAdminNode(p string) (*models.TreeNode, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &admin_tree_service.StatAdminTreeParams{
+		Body:    &models.TreeReadNodeRequest{Node: &models.TreeNode{Path: p}},
+		Context: ctx,
+	}
+	res, err := client.AdminTreeService.StatAdminTree(params)
+	if err != nil {
+		return nil, err
+	}
+	if res.Payload.Node == nil {
+		return nil, fmt.Errorf("no node found at %s", p)
+	}
+	return res.Payload.Node, nil
+}
@@ -0,0 +1,207 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/meta_service"
+	"github.com/pydio/cells-sdk-go/v3/client/share_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// Kinds of cell members.
+const (
+	MemberUser  = "user"
+	MemberGroup = "group"
+	MemberRole  = "role"
+)
+
+// CellMember describes an entity that has access to a cell, with its rights.
+type CellMember struct {
+	Kind  string
+	ID    string
+	Read  bool
+	Write bool
+}
+
+// CellSummary holds the information about a cell that are returned when listing workspaces.
+type CellSummary struct {
+	UUID        string
+	Slug        string
+	Label       string
+	Description string
+	Permissions string
+}
+
+// ParseCellMember parses a member definition of the form kind:id:rights, where
+// kind is one of user, group or role, and rights is r, w or rw. Rights default to rw.
+func ParseCellMember(def string) (*CellMember, error) {
+	parts := strings.Split(def, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid member %s, expected kind:id[:rights], e.g. user:alice:rw", def)
+	}
+	m := &CellMember{Kind: parts[0], ID: parts[1], Read: true, Write: true}
+	switch m.Kind {
+	case MemberUser, MemberGroup, MemberRole:
+	default:
+		return nil, fmt.Errorf("invalid member kind %s in %s, expected one of user, group or role", m.Kind, def)
+	}
+	if len(parts) == 3 {
+		rights := parts[2]
+		if rights == "" || strings.Trim(rights, "rw") != "" {
+			return nil, fmt.Errorf("invalid rights %s in %s, expected r, w or rw", rights, def)
+		}
+		m.Read = strings.Contains(rights, "r")
+		m.Write = strings.Contains(rights, "w")
+	}
+	return m, nil
+}
+
+// ToCellAcl resolves the member on the server and builds the corresponding cell ACL.
+func (m *CellMember) ToCellAcl() (*models.RestCellACL, error) {
+	acl := &models.RestCellACL{}
+	switch m.Kind {
+	case MemberUser:
+		u, err := FindUser(m.ID)
+		if err != nil {
+			return nil, err
+		}
+		acl.RoleID = u.UUID
+		acl.IsUserRole = true
+		acl.User = u
+	case MemberGroup:
+		g, err := FindGroup(m.ID)
+		if err != nil {
+			return nil, err
+		}
+		acl.RoleID = g.UUID
+		acl.Group = g
+	default:
+		acl.RoleID = m.ID
+	}
+	if m.Read {
+		acl.Actions = append(acl.Actions, &models.IdmACLAction{Name: "read", Value: "1"})
+	}
+	if m.Write {
+		acl.Actions = append(acl.Actions, &models.IdmACLAction{Name: "write", Value: "1"})
+	}
+	return acl, nil
+}
+
+// ListCells retrieves the cells the current user has access to.
+func ListCells() ([]*CellSummary, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &meta_service.GetBulkMetaParams{
+		Body:    &models.RestGetBulkMetaRequest{NodePaths: []string{"/*"}},
+		Context: ctx,
+	}
+	res, err := client.MetaService.GetBulkMeta(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list cells: %s", err.Error())
+	}
+	var cells []*CellSummary
+	for _, n := range res.Payload.Nodes {
+		if n.MetaStore == nil || n.MetaStore["ws_scope"] != "\"ROOM\"" {
+			continue
+		}
+		cells = append(cells, &CellSummary{
+			UUID:        strings.Trim(n.MetaStore["ws_uuid"], "\""),
+			Slug:        strings.Trim(n.Path, "/"),
+			Label:       strings.Trim(n.MetaStore["ws_label"], "\""),
+			Description: strings.Trim(n.MetaStore["ws_description"], "\""),
+			Permissions: strings.Trim(n.MetaStore["ws_permissions"], "\""),
+		})
+	}
+	return cells, nil
+}
+
+// FindCell retrieves the full definition of a cell given its UUID, slug or label.
+func FindCell(id string) (*models.RestCell, error) {
+	cells, err := ListCells()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cells {
+		if c.UUID == id || c.Slug == id || c.Label == id {
+			return GetCell(c.UUID)
+		}
+	}
+	return nil, fmt.Errorf("no cell found with ID, slug or label %s", id)
+}
+
+// GetCell retrieves the full definition of a cell, including its members.
+func GetCell(cellUUID string) (*models.RestCell, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &share_service.GetCellParams{UUID: cellUUID, Context: ctx}
+	res, err := client.ShareService.GetCell(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve cell %s: %s", cellUUID, err.Error())
+	}
+	return res.Payload, nil
+}
+
+// PutCell creates or updates a cell. When creating a cell without root nodes, an empty root folder is created.
+func PutCell(cell *models.RestCell) (*models.RestCell, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &share_service.PutCellParams{
+		Body: &models.RestPutCellRequest{
+			Room:            cell,
+			CreateEmptyRoot: cell.UUID == "" && len(cell.RootNodes) == 0,
+		},
+		Context: ctx,
+	}
+	res, err := client.ShareService.PutCell(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not save cell %s: %s", cell.Label, err.Error())
+	}
+	return res.Payload, nil
+}
+
+// DeleteCell removes a cell. The shared resources are left untouched.
+func DeleteCell(cellUUID string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &share_service.DeleteCellParams{UUID: cellUUID, Context: ctx}
+	if _, err = client.ShareService.DeleteCell(params); err != nil {
+		return fmt.Errorf("could not delete cell %s: %s", cellUUID, err.Error())
+	}
+	return nil
+}
+
+// CellAclMember returns a human-readable description of the member that is targeted by a cell ACL.
+func CellAclMember(acl *models.RestCellACL) (kind string, id string) {
+	switch {
+	case acl.User != nil:
+		return MemberUser, acl.User.Login
+	case acl.Group != nil:
+		return MemberGroup, GroupFullPath(acl.Group)
+	case acl.Role != nil:
+		return MemberRole, acl.Role.Label
+	}
+	return MemberRole, acl.RoleID
+}
+
+// CellAclRights returns the rights of a cell ACL, as r, w or rw.
+func CellAclRights(acl *models.RestCellACL) string {
+	var rights string
+	for _, a := range acl.Actions {
+		switch a.Name {
+		case "read":
+			rights += "r"
+		case "write":
+			rights += "w"
+		}
+	}
+	return rights
+}
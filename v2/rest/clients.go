@@ -2,14 +2,14 @@ package rest
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 
 	openapiruntime "github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
@@ -18,7 +18,6 @@ import (
 	cells_sdk "github.com/pydio/cells-sdk-go/v3"
 	"github.com/pydio/cells-sdk-go/v3/client"
 	"github.com/pydio/cells-sdk-go/v3/transport"
-	sdk_rest "github.com/pydio/cells-sdk-go/v3/transport/rest"
 
 	"github.com/pydio/cells-client/v2/common"
 )
@@ -35,8 +34,11 @@ var (
 // CecConfig extends the default SdkConfig with custom parameters.
 type CecConfig struct {
 	cells_sdk.SdkConfig
+	TransportSettings
 	Label            string `json:"label"`
 	SkipKeyring      bool   `json:"skipKeyring"`
+	SecretBackend    string `json:"secretBackend,omitempty"`
+	SecretCommand    string `json:"secretCommand,omitempty"`
 	AuthType         string `json:"authType"`
 	CreatedAtVersion string `json:"createdAtVersion"`
 }
@@ -50,10 +52,10 @@ func GetApiClient(anonymous ...bool) (context.Context, *client.PydioCellsRestAPI
 	if len(anonymous) > 0 && anonymous[0] {
 		anon = true
 	}
-	DefaultConfig.CustomHeaders = map[string]string{"User-Agent": common.AppName + "/" + common.Version}
+	addUserAgent(DefaultConfig)
 	var err error
 	once.Do(func() {
-		DefaultContext, DefaultTransport, err = sdk_rest.GetClientTransport(&DefaultConfig.SdkConfig, anon)
+		DefaultContext, DefaultTransport, err = newRestTransport(DefaultConfig, anon)
 	})
 
 	if err != nil {
@@ -73,22 +75,22 @@ func AuthenticatedGet(uri string) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	return AuthenticatedRequest(req, &DefaultConfig.SdkConfig)
+	return AuthenticatedRequest(req, DefaultConfig)
 }
 
-// AuthenticatedRequest performs the passed request after adding an authorization Header.
-func AuthenticatedRequest(req *http.Request, sdkConfig *cells_sdk.SdkConfig) (*http.Response, error) {
+// AuthenticatedRequest performs the passed request after adding an authorization Header,
+// with the TLS and proxy settings of the passed config.
+func AuthenticatedRequest(req *http.Request, c *CecConfig) (*http.Response, error) {
 
-	tp, e := transport.TokenProviderFromConfig(sdkConfig)
+	tp, e := newTokenProvider(c)
 	if e != nil {
 		return nil, e
 	}
 
-	httpClient := &http.Client{Transport: transport.New(
-		transport.WithSkipVerify(sdkConfig.SkipVerify),
-		transport.WithCustomHeaders(sdkConfig.CustomHeaders),
-		transport.WithBearer(tp),
-	)}
+	httpClient, e := newProfileClient(c, tp)
+	if e != nil {
+		return nil, e
+	}
 
 	return httpClient.Do(req)
 }
@@ -125,30 +127,147 @@ func DefaultConfigFilePath() string {
 	return filepath.Join(f, "config.json")
 }
 
-var refreshMux = &sync.Mutex{}
+var (
+	// refreshMux serializes the refreshes within the process.
+	refreshMux = &sync.Mutex{}
+	// tokenMux guards the tokens of the configs, that are refreshed in the background while requests are sent.
+	tokenMux = &sync.RWMutex{}
+)
+
+// tokens returns the tokens of the config. Use it rather than the fields of the config when the tokens
+// may be refreshed concurrently, e.g. by StartBackgroundRefresh.
+func (c *CecConfig) tokens() (idToken, refreshToken string, expiresAt int) {
+	tokenMux.RLock()
+	defer tokenMux.RUnlock()
+	return c.IdToken, c.RefreshToken, c.TokenExpiresAt
+}
+
+// setTokens replaces the tokens of the config, so that concurrent readers that use tokens see the new ones.
+func (c *CecConfig) setTokens(idToken, refreshToken string, expiresAt int) {
+	tokenMux.Lock()
+	defer tokenMux.Unlock()
+	c.IdToken, c.RefreshToken, c.TokenExpiresAt = idToken, refreshToken, expiresAt
+}
+
+// sdkConfig returns a copy of the SDK config, that can be safely read while the tokens are refreshed.
+func (c *CecConfig) sdkConfig() cells_sdk.SdkConfig {
+	tokenMux.RLock()
+	defer tokenMux.RUnlock()
+	return c.SdkConfig
+}
+
+// configTokenProvider provides the current token of an OAuth2 or personal access token config.
+type configTokenProvider struct {
+	c *CecConfig
+}
+
+func (p *configTokenProvider) Retrieve() (string, error) {
+	idToken, _, _ := p.c.tokens()
+	if idToken == "" {
+		return "", fmt.Errorf("no token is defined for %s", p.c.Url)
+	}
+	return idToken, nil
+}
+
+// newTokenProvider returns the provider of the token that authenticates the requests of the passed config.
+// Tokens of OAuth2 configs are read at each request, as they may be refreshed in the background.
+func newTokenProvider(c *CecConfig) (tokenProvider, error) {
+	if c.AuthType == common.ClientAuthType {
+		// The SDK retrieves and caches a token with the login and password of the config
+		return transport.TokenProviderFromConfig(&c.SdkConfig)
+	}
+	return &configTokenProvider{c: c}, nil
+}
+
+const backgroundRefreshInterval = 30 * time.Second
 
-func RefreshAndStoreIfRequired(c *CecConfig) bool {
+// RefreshAndStoreIfRequired refreshes the tokens of the passed config if they have expired, and stores the new ones
+// in the corresponding profile. The config file stays locked during the refresh, so that concurrent processes
+// do not all consume the same refresh token: the ones that come next reuse the tokens of the first one.
+func RefreshAndStoreIfRequired(c *CecConfig) (bool, error) {
 	refreshMux.Lock()
 	defer refreshMux.Unlock()
 
-	refreshed, err := RefreshIfRequired(c)
-	if err != nil {
-		log.Fatal("Could not refresh authentication token:", err)
+	if !needsRefresh(c) {
+		return false, nil
 	}
-	if refreshed {
-		// Copy config as IdToken will be cleared
-		storeConfig := *c
-		if !c.SkipKeyring {
-			if err := ConfigToKeyring(&storeConfig); err != nil {
-				return false
+
+	var refreshed bool
+	err := UpdateConfigList(func(list *ConfigList) error {
+		stored, ok := list.Configs[createID(c)]
+		if !ok {
+			// Profile is not stored, e.g. it is defined by environment variables: only refresh in memory
+			var e error
+			if refreshed, e = RefreshIfRequired(c); e != nil {
+				return e
+			}
+			return ErrSkipConfigSave
+		}
+
+		// Work on a copy, as sensitive information is cleared when it is put in the keyring
+		latest := *stored
+		if !latest.SkipKeyring {
+			if e := ConfigFromKeyring(&latest); e != nil {
+				return e
+			}
+		}
+		if _, current, _ := c.tokens(); latest.RefreshToken != "" && latest.RefreshToken != current {
+			// Another process has already refreshed the tokens: ours have been consumed
+			c.setTokens(latest.IdToken, latest.RefreshToken, latest.TokenExpiresAt)
+			if !needsRefresh(c) {
+				refreshed = true
+				return ErrSkipConfigSave
 			}
 		}
-		// Save config to renew TokenExpireAt
-		confData, _ := json.MarshalIndent(&storeConfig, "", "\t")
-		ioutil.WriteFile(GetConfigFilePath(), confData, 0600)
+
+		var e error
+		if refreshed, e = RefreshIfRequired(c); e != nil {
+			return e
+		}
+		latest.IdToken, latest.RefreshToken, latest.TokenExpiresAt = c.tokens()
+		if !latest.SkipKeyring {
+			if e = ConfigToKeyring(&latest); e != nil {
+				return fmt.Errorf("could not store the refreshed tokens: %s", e.Error())
+			}
+		}
+		list.Configs[createID(c)] = &latest
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not refresh authentication token: %s", err.Error())
 	}
 
-	return refreshed
+	return refreshed, nil
+}
+
+// StartBackgroundRefresh periodically refreshes the tokens of the default config, so that long transfers
+// never use an expired token. It returns a function that stops the refresh.
+func StartBackgroundRefresh() (stop func()) {
+	interval := backgroundRefreshInterval
+	if RefreshMargin > 0 && RefreshMargin/2 < interval {
+		// Make sure that the tokens are checked at least once within the margin
+		interval = RefreshMargin / 2
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var lastErr string
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := RefreshAndStoreIfRequired(DefaultConfig); err != nil && err.Error() != lastErr {
+					// Requests fail on their own if the token expires: only warn once per error
+					log.Println("[WARNING]", err.Error())
+					lastErr = err.Error()
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
 }
 
 func getS3ConfigFromSdkConfig(sConf *CecConfig) cells_sdk.S3Config {
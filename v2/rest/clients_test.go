@@ -0,0 +1,438 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	cells_sdk "github.com/pydio/cells-sdk-go/v3"
+)
+
+// rotatingTokenServer is an OAuth2 token endpoint that issues a new refresh token at each refresh,
+// and rejects the refresh tokens that have already been consumed.
+type rotatingTokenServer struct {
+	*httptest.Server
+	mux  sync.Mutex
+	gen  int
+	hits int
+}
+
+func newRotatingTokenServer() *rotatingTokenServer {
+	s := &rotatingTokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oidc/oauth2/token" || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "refresh_token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		s.hits++
+		if r.PostForm.Get("refresh_token") != s.refreshToken() {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"refresh token has already been used"}`)
+			return
+		}
+		// Slow down the refresh, so that concurrent clients really have to wait for each other
+		time.Sleep(50 * time.Millisecond)
+		s.gen++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", s.gen),
+			"refresh_token": s.refreshToken(),
+			"expires_in":    3600,
+		})
+	}))
+	return s
+}
+
+func (s *rotatingTokenServer) refreshToken() string {
+	return fmt.Sprintf("refresh-%d", s.gen)
+}
+
+func (s *rotatingTokenServer) stats() (gen, hits int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.gen, s.hits
+}
+
+// setUpRefreshConfig stores a config file with an OAuth2 profile whose tokens have expired, next to another profile.
+func setUpRefreshConfig(t *testing.T, serverURL string) (*CecConfig, *CecConfig) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "cec-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := configFilePath
+	SetConfigFilePath(filepath.Join(dir, "config.json"))
+	t.Cleanup(func() {
+		SetConfigFilePath(previous)
+		_ = os.RemoveAll(dir)
+	})
+
+	oauth := &CecConfig{
+		SdkConfig: cells_sdk.SdkConfig{
+			Url:            serverURL,
+			User:           "alice",
+			IdToken:        "access-0",
+			RefreshToken:   "refresh-0",
+			TokenExpiresAt: int(time.Now().Add(-time.Minute).Unix()),
+		},
+		Label:       "alice@test",
+		SkipKeyring: true,
+		AuthType:    "oauth",
+	}
+	other := &CecConfig{
+		SdkConfig: cells_sdk.SdkConfig{
+			Url:      "https://files.example.com",
+			User:     "bob",
+			Password: "secret",
+		},
+		Label:       "bob@files.example.com",
+		SkipKeyring: true,
+		AuthType:    "client-auth",
+	}
+	err = UpdateConfigList(func(list *ConfigList) error {
+		list.Configs[createID(oauth)] = oauth
+		list.Configs[createID(other)] = other
+		list.ActiveConfigID = createID(other)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oauth, other
+}
+
+// expireStoredTokens makes the tokens of the stored profile expire, and returns a copy of the profile,
+// as loaded by a process before the refresh.
+func expireStoredTokens(t *testing.T, id string) *CecConfig {
+	t.Helper()
+	var stale CecConfig
+	err := UpdateConfigList(func(list *ConfigList) error {
+		c := list.Configs[id]
+		c.TokenExpiresAt = int(time.Now().Add(-time.Minute).Unix())
+		stale = *c
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &stale
+}
+
+// checkStoredConfig verifies that the config file can still be parsed and that it holds all profiles,
+// with the latest tokens for the refreshed one.
+func checkStoredConfig(t *testing.T, oauth, other *CecConfig, refreshToken string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(GetConfigFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list ConfigList
+	if err = json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("config file is not a valid config list: %v\n%s", err, data)
+	}
+	if len(list.Configs) != 2 {
+		t.Fatalf("config file holds %d profiles, want 2", len(list.Configs))
+	}
+	if list.ActiveConfigID != createID(other) {
+		t.Errorf("active profile = %s, want %s", list.ActiveConfigID, createID(other))
+	}
+	stored := list.Configs[createID(oauth)]
+	if stored == nil {
+		t.Fatalf("profile %s has been lost", createID(oauth))
+	}
+	if stored.RefreshToken != refreshToken || needsRefresh(stored) {
+		t.Errorf("stored profile has refresh token %s expiring at %d, want fresh %s", stored.RefreshToken, stored.TokenExpiresAt, refreshToken)
+	}
+	if stored.Label != oauth.Label || stored.AuthType != oauth.AuthType {
+		t.Errorf("stored profile has lost its metadata: %+v", stored)
+	}
+	if o := list.Configs[createID(other)]; o == nil || o.Password != other.Password || o.Label != other.Label {
+		t.Errorf("profile %s has been modified: %+v", createID(other), o)
+	}
+}
+
+func TestRefreshAndStoreIfRequiredConcurrentGoroutines(t *testing.T) {
+	srv := newRotatingTokenServer()
+	defer srv.Close()
+	oauth, other := setUpRefreshConfig(t, srv.URL)
+
+	const clients = 8
+	for rotation := 1; rotation <= 3; rotation++ {
+		stale := expireStoredTokens(t, createID(oauth))
+		var wg sync.WaitGroup
+		errs := make(chan error, clients)
+		confs := make([]*CecConfig, clients)
+		for i := 0; i < clients; i++ {
+			c := *stale
+			confs[i] = &c
+			wg.Add(1)
+			go func(c *CecConfig) {
+				defer wg.Done()
+				if _, err := RefreshAndStoreIfRequired(c); err != nil {
+					errs <- err
+				}
+			}(confs[i])
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Errorf("rotation %d: %v", rotation, err)
+		}
+
+		gen, hits := srv.stats()
+		if gen != rotation || hits != rotation {
+			t.Fatalf("rotation %d: token endpoint issued %d tokens for %d requests, want %d", rotation, gen, hits, rotation)
+		}
+		for _, c := range confs {
+			if c.RefreshToken != srv.refreshToken() {
+				t.Errorf("rotation %d: client still uses refresh token %s", rotation, c.RefreshToken)
+			}
+		}
+		checkStoredConfig(t, oauth, other, srv.refreshToken())
+	}
+}
+
+const (
+	helperProcessEnv = "CEC_TEST_REFRESH_HELPER"
+	helperConfigEnv  = "CEC_TEST_REFRESH_CONFIG"
+	helperProfileEnv = "CEC_TEST_REFRESH_PROFILE"
+)
+
+// TestRefreshHelperProcess is not a real test: it is run as a separate process by
+// TestRefreshAndStoreIfRequiredConcurrentProcesses, to refresh the passed profile.
+func TestRefreshHelperProcess(t *testing.T) {
+	if os.Getenv(helperProcessEnv) != "1" {
+		t.Skip("only run as a helper process")
+	}
+	SetConfigFilePath(os.Getenv(helperConfigEnv))
+	var c CecConfig
+	if err := json.Unmarshal([]byte(os.Getenv(helperProfileEnv)), &c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RefreshAndStoreIfRequired(&c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshAndStoreIfRequiredConcurrentProcesses(t *testing.T) {
+	if os.Getenv(helperProcessEnv) == "1" {
+		return
+	}
+	srv := newRotatingTokenServer()
+	defer srv.Close()
+	oauth, other := setUpRefreshConfig(t, srv.URL)
+
+	const processes = 4
+	for rotation := 1; rotation <= 2; rotation++ {
+		stale := expireStoredTokens(t, createID(oauth))
+		profile, _ := json.Marshal(stale)
+		var wg sync.WaitGroup
+		for i := 0; i < processes; i++ {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestRefreshHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				helperProcessEnv+"=1",
+				helperConfigEnv+"="+GetConfigFilePath(),
+				helperProfileEnv+"="+string(profile),
+			)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("rotation %d: helper process failed: %v\n%s", rotation, err, out)
+				}
+			}()
+		}
+		wg.Wait()
+
+		gen, hits := srv.stats()
+		if gen != rotation || hits != rotation {
+			t.Fatalf("rotation %d: token endpoint issued %d tokens for %d requests, want %d", rotation, gen, hits, rotation)
+		}
+		checkStoredConfig(t, oauth, other, srv.refreshToken())
+	}
+}
+
+func TestLockFileWaitsForRelease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cec-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "config.json")
+
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan func())
+	go func() {
+		u, e := lockFile(filePath)
+		if e != nil {
+			t.Error(e)
+			u = func() {}
+		}
+		acquired <- u
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock has been acquired twice")
+	case <-time.After(5 * lockRetryInterval):
+	}
+	unlock()
+	select {
+	case u := <-acquired:
+		u()
+	case <-time.After(time.Second):
+		t.Fatal("lock has not been acquired once released")
+	}
+}
+
+const helperLockEnv = "CEC_TEST_LOCK_HELPER"
+
+// TestLockHelperProcess is not a real test: it is run as a separate process by TestLockFileReleasedOnCrash,
+// to take the lock and exit without releasing it.
+func TestLockHelperProcess(t *testing.T) {
+	path := os.Getenv(helperLockEnv)
+	if path == "" {
+		t.Skip("only run as a helper process")
+	}
+	if _, err := lockFile(path); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	// Wait until killed
+	time.Sleep(time.Minute)
+	os.Exit(2)
+}
+
+func TestLockFileReleasedOnCrash(t *testing.T) {
+	if os.Getenv(helperLockEnv) != "" {
+		return
+	}
+	dir, err := ioutil.TempDir("", "cec-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "config.json")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	cmd.Env = append(os.Environ(), helperLockEnv+"="+filePath)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line := make([]byte, 6)
+	if _, err = io.ReadFull(out, line); err != nil || string(line) != "locked" {
+		t.Fatalf("helper process could not take the lock: %q %v", line, err)
+	}
+
+	// The lock is held by the other process
+	locked, err := tryLockPath(filePath)
+	if err != nil || locked {
+		t.Fatalf("lock should be held by the helper process: %v %v", locked, err)
+	}
+
+	// Simulate a crash: the system releases the lock
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	start := time.Now()
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("lock has only been acquired after %s", d)
+	}
+}
+
+// tryLockPath checks if the lock of the passed file is free, without waiting.
+func tryLockPath(filePath string) (bool, error) {
+	f, err := os.OpenFile(filePath+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	locked, err := tryLock(f)
+	if locked {
+		_ = unlock(f)
+	}
+	return locked, err
+}
+
+// TestRefreshWhileReadingTokens checks that the tokens can be read by requests while they are refreshed
+// in the background. Run it with -race to detect unsynchronized accesses.
+func TestRefreshWhileReadingTokens(t *testing.T) {
+	srv := newRotatingTokenServer()
+	defer srv.Close()
+	oauth, _ := setUpRefreshConfig(t, srv.URL)
+
+	// Make sure that each call refreshes the tokens
+	previousMargin := RefreshMargin
+	RefreshMargin = 2 * time.Hour
+	defer func() { RefreshMargin = previousMargin }()
+
+	c := *oauth
+	tp, err := newTokenProvider(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := &tokenCredentials{c: &c, secret: "gatewaysecret"}
+
+	const refreshes = 5
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if token, e := tp.Retrieve(); e != nil || token == "" {
+					t.Errorf("could not retrieve token: %q %v", token, e)
+					return
+				}
+				if v, e := creds.Retrieve(); e != nil || v.AccessKeyID == "" {
+					t.Errorf("could not retrieve S3 credentials: %v %v", v, e)
+					return
+				}
+				_ = GetTokenStatus(&c)
+				_ = c.sdkConfig()
+			}
+		}()
+	}
+	for i := 0; i < refreshes; i++ {
+		if _, err := RefreshAndStoreIfRequired(&c); err != nil {
+			t.Error(err)
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if gen, _ := srv.stats(); gen != refreshes {
+		t.Errorf("tokens have been refreshed %d times, want %d", gen, refreshes)
+	}
+	if token, _ := tp.Retrieve(); token != fmt.Sprintf("access-%d", refreshes) {
+		t.Errorf("token provider returns %s, want the latest token", token)
+	}
+}
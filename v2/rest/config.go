@@ -20,6 +20,24 @@ type ConfigList struct {
 
 // GetConfigList retrieves the current configurations stored in the config.json file.
 func GetConfigList() (*ConfigList, error) {
+	configList, migrated, err := readConfigList()
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		err = UpdateConfigList(func(list *ConfigList) error {
+			*list = *configList
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not save after config migration: %s", err.Error())
+		}
+	}
+	return configList, nil
+}
+
+// readConfigList reads the config file, and tells if it had to be migrated from the legacy single config format.
+func readConfigList() (*ConfigList, bool, error) {
 
 	var configList ConfigList
 
@@ -27,22 +45,26 @@ func GetConfigList() (*ConfigList, error) {
 	data, err := ioutil.ReadFile(GetConfigFilePath())
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return &ConfigList{Configs: make(map[string]*CecConfig)}, nil
+			return &ConfigList{Configs: make(map[string]*CecConfig)}, false, nil
 		} else {
-			return nil, err
+			return nil, false, err
 		}
 	}
 
 	err = json.Unmarshal(data, &configList)
 	if err != nil {
-		return nil, fmt.Errorf("unknown config format: %s", err)
+		return nil, false, fmt.Errorf("unknown config format: %s", err)
 	}
 
 	// Double-check to detect and migrate legacy configs
 	if configList.Configs == nil || len(configList.Configs) == 0 {
 		var oldConf *CecConfig
 		if err = json.Unmarshal(data, &oldConf); err != nil {
-			return nil, fmt.Errorf("unknown config format: %s", err)
+			return nil, false, fmt.Errorf("unknown config format: %s", err)
+		}
+		if oldConf == nil || oldConf.Url == "" {
+			// Empty list, nothing to migrate
+			return &ConfigList{ActiveConfigID: configList.ActiveConfigID, Configs: make(map[string]*CecConfig)}, false, nil
 		}
 
 		id := createID(oldConf)
@@ -55,13 +77,44 @@ func GetConfigList() (*ConfigList, error) {
 			Configs:        configs,
 			ActiveConfigID: id,
 		}
-		err = configList.SaveConfigFile()
-		if err != nil {
-			return nil, fmt.Errorf("could not save after config migration: %s", err.Error())
-		}
+		return &configList, true, nil
 	}
 
-	return &configList, nil
+	return &configList, false, nil
+}
+
+// ErrSkipConfigSave can be returned by the function that is passed to UpdateConfigList to leave the config file untouched.
+var ErrSkipConfigSave = errors.New("skip config save")
+
+// UpdateConfigList is the only way to modify the config file. It locks the file, so that concurrent processes
+// wait for each other, reads the latest version of the profile list, applies the modification and atomically
+// replaces the file. Modifications made by other processes between the read and the write are thus never lost.
+func UpdateConfigList(modify func(list *ConfigList) error) error {
+	filePath := GetConfigFilePath()
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	list, _, err := readConfigList()
+	if err != nil {
+		return err
+	}
+	if err = modify(list); err != nil {
+		if errors.Is(err, ErrSkipConfigSave) {
+			return nil
+		}
+		return err
+	}
+	if list.Configs == nil {
+		list.Configs = make(map[string]*CecConfig)
+	}
+	confData, _ := json.MarshalIndent(list, "", "\t")
+	if err = writeFilePrivate(filePath, confData); err != nil {
+		return fmt.Errorf("could not save the config file, cause: %s", err)
+	}
+	return nil
 }
 
 func UpdateConfig(newConf *CecConfig) error {
@@ -88,19 +141,28 @@ func UpdateConfig(newConf *CecConfig) error {
 		newConf.SkipKeyring = true
 	}
 
-	cl, err := GetConfigList()
-	if err != nil {
-		return err
-	}
-
 	id := createID(newConf)
 	newConf.Label = createLabel(newConf)
 	newConf.CreatedAtVersion = common.Version
 
-	cl.Configs[id] = newConf
-	cl.ActiveConfigID = id
-
-	return cl.SaveConfigFile()
+	err = UpdateConfigList(func(cl *ConfigList) error {
+		if old, ok := cl.Configs[id]; ok {
+			// Logging in again keeps the metadata that has been set on the profile
+			newConf.Label = old.Label
+			for k, v := range old.CustomHeaders {
+				if _, ok := newConf.CustomHeaders[k]; !ok {
+					if newConf.CustomHeaders == nil {
+						newConf.CustomHeaders = map[string]string{}
+					}
+					newConf.CustomHeaders[k] = v
+				}
+			}
+		}
+		cl.Configs[id] = newConf
+		cl.ActiveConfigID = id
+		return nil
+	})
+	return err
 }
 
 // Remove removes a config from the list of available configurations by its ID.
@@ -124,10 +186,19 @@ func (list *ConfigList) SetActiveConfig(id string) error {
 }
 
 func (list *ConfigList) GetActiveConfig() (*CecConfig, error) {
-	c := list.Configs[list.ActiveConfigID]
-	if c == nil {
+	c, err := list.GetConfig(list.ActiveConfigID)
+	if err != nil {
 		return nil, fmt.Errorf("active config not found")
 	}
+	return c, nil
+}
+
+// GetConfig retrieves a config by its ID, with the sensitive information that is stored in the keyring.
+func (list *ConfigList) GetConfig(id string) (*CecConfig, error) {
+	c, err := list.GetStoredConfig(id)
+	if err != nil {
+		return nil, err
+	}
 	if !c.SkipKeyring {
 		if err := ConfigFromKeyring(c); err != nil {
 			return nil, err
@@ -136,6 +207,37 @@ func (list *ConfigList) GetActiveConfig() (*CecConfig, error) {
 	return c, nil
 }
 
+// GetStoredConfig retrieves a config by its ID, as it is stored in the config file: the sensitive information
+// that is stored in the keyring is not loaded, see ConfigFromKeyring.
+func (list *ConfigList) GetStoredConfig(id string) (*CecConfig, error) {
+	c := list.Configs[id]
+	if c == nil {
+		return nil, fmt.Errorf("config not found, ID is not valid [%s]", id)
+	}
+	return c, nil
+}
+
+// FindConfigID retrieves the ID of a config given its ID or its label.
+func (list *ConfigList) FindConfigID(idOrLabel string) (string, error) {
+	if _, ok := list.Configs[idOrLabel]; ok {
+		return idOrLabel, nil
+	}
+	var found []string
+	for id, c := range list.Configs {
+		if c.Label == idOrLabel {
+			found = append(found, id)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no profile found with ID or label %s", idOrLabel)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("several profiles have label %s, please use the ID instead", idOrLabel)
+	}
+}
+
 func createID(c *CecConfig) string {
 	var port string
 	u, _ := url.Parse(c.Url)
@@ -157,11 +259,11 @@ func createLabel(c *CecConfig) string {
 	return fmt.Sprintf("%s@%s", c.User, u.Hostname())
 }
 
-// SaveConfigFile saves inside the config file.
+// SaveConfigFile replaces the whole content of the config file with this list.
+// Prefer UpdateConfigList, that does not overwrite the changes made by other processes in the meantime.
 func (list *ConfigList) SaveConfigFile() error {
-	confData, _ := json.MarshalIndent(&list, "", "\t")
-	if err := ioutil.WriteFile(GetConfigFilePath(), confData, 0666); err != nil {
-		return fmt.Errorf("could not save the config file, cause: %s", err)
-	}
-	return nil
+	return UpdateConfigList(func(stored *ConfigList) error {
+		*stored = *list
+		return nil
+	})
 }
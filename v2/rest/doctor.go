@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-version"
+
+	"github.com/pydio/cells-client/v2/common"
+)
+
+// MinServerVersion is the oldest version of Cells that this client is known to work with.
+const MinServerVersion = "3.0.0"
+
+const probeTimeout = 10 * time.Second
+
+// CheckWarning is returned by checks that pass, but that found something that should be fixed.
+type CheckWarning struct {
+	Message string
+}
+
+func (w *CheckWarning) Error() string {
+	return w.Message
+}
+
+// CheckDNS resolves the host of the server of the passed config.
+func CheckDNS(c *CecConfig) (string, error) {
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %s: %s", c.Url, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+	if err != nil {
+		if c.ProxyURL != "" {
+			return "", &CheckWarning{Message: err.Error() + ", this is expected if the server is only known by the proxy"}
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%s resolves to %s", u.Hostname(), strings.Join(addrs, ", ")), nil
+}
+
+// CheckTLS performs a TLS handshake with the server of the passed config, using its CA and client certificate,
+// and describes the certificate that is presented by the server.
+func CheckTLS(c *CecConfig) (string, error) {
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %s: %s", c.Url, err.Error())
+	}
+	if u.Scheme != "https" {
+		return "not used, the server URL is not https", nil
+	}
+	tr, err := NewHTTPTransport(c)
+	if err != nil {
+		return "", err
+	}
+	if c.ProxyURL != "" {
+		// The handshake goes through the proxy tunnel: let the bootconf check validate it
+		return "checked through the proxy by the next step", nil
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+	tlsConfig := tr.TLSClientConfig.Clone()
+	tlsConfig.ServerName = u.Hostname()
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: probeTimeout}, "tcp", host, tlsConfig)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return "", fmt.Errorf("no certificate presented by the server")
+	}
+	leaf := certs[0]
+	detail := fmt.Sprintf("%s, issued by %s, valid until %s", leaf.Subject.CommonName, leaf.Issuer.CommonName, leaf.NotAfter.Format("2006-01-02"))
+	if c.SkipVerify {
+		return detail, &CheckWarning{Message: "certificate is not verified because skipVerify is set: " + detail}
+	}
+	if remaining := time.Until(leaf.NotAfter); remaining < 15*24*time.Hour {
+		return detail, &CheckWarning{Message: "certificate expires in less than 15 days: " + detail}
+	}
+	return detail, nil
+}
+
+// CheckBootconf checks that the public boot configuration of the server can be retrieved, without authentication.
+func CheckBootconf(c *CecConfig) (string, error) {
+	client, err := NewHTTPClient(c)
+	if err != nil {
+		return "", err
+	}
+	client.Timeout = probeTimeout
+	resp, err := client.Get(c.Url + "/a/frontend/bootconf")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("received status code %d - %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return fmt.Sprintf("status %d", resp.StatusCode), nil
+}
+
+// CheckServerVersion retrieves the version of the server and checks that it is supported by this client.
+func CheckServerVersion() (string, error) {
+	sv, err := RetrieveRemoteServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s", sv.PackageLabel, sv.Version), CheckServerCompatibility(sv)
+}
+
+// CheckServerCompatibility checks that the version of the server is supported by this client.
+// A version that cannot be parsed only produces a warning.
+func CheckServerCompatibility(sv *common.ServerVersion) error {
+	current, err := version.NewVersion(sv.Version)
+	if err != nil {
+		return &CheckWarning{Message: fmt.Sprintf("could not parse server version %s: %s", sv.Version, err.Error())}
+	}
+	if current.LessThan(version.Must(version.NewVersion(MinServerVersion))) {
+		return fmt.Errorf("server version %s is older than %s, the oldest supported version", sv.Version, MinServerVersion)
+	}
+	return nil
+}
+
+// CheckS3Gateway checks that the S3 gateway of the server, that is used to transfer files, answers.
+func CheckS3Gateway() (string, error) {
+	s3Client, bucketName, err := GetS3Client()
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	_, err = s3Client.ListObjectsWithContext(ctx, (&s3.ListObjectsInput{}).
+		SetBucket(bucketName).
+		SetDelimiter("/").
+		SetMaxKeys(1),
+	)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("bucket %s is reachable", bucketName), nil
+}
+
+// CheckReadWrite uploads a small file in the passed folder, reads it back and removes it.
+func CheckReadWrite(folder string) (string, error) {
+	s3Client, bucketName, err := GetS3Client()
+	if err != nil {
+		return "", err
+	}
+	key := path.Join(strings.Trim(folder, "/"), ".cec-doctor-"+uuid.New().String()+".txt")
+	content := []byte("Written by the doctor command of " + DefaultConfig.Label + " at " + time.Now().Format(time.RFC3339))
+
+	if _, err = s3Client.PutObject((&s3.PutObjectInput{}).
+		SetBucket(bucketName).
+		SetKey(key).
+		SetBody(bytes.NewReader(content)),
+	); err != nil {
+		return "", fmt.Errorf("could not write %s: %s", key, err.Error())
+	}
+	// Always try to clean up, even if the read fails
+	deleted := false
+	defer func() {
+		if !deleted {
+			_, _ = s3Client.DeleteObject((&s3.DeleteObjectInput{}).SetBucket(bucketName).SetKey(key))
+		}
+	}()
+
+	obj, err := s3Client.GetObject((&s3.GetObjectInput{}).SetBucket(bucketName).SetKey(key))
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %s", key, err.Error())
+	}
+	read, err := ioutil.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %s", key, err.Error())
+	}
+	if !bytes.Equal(read, content) {
+		return "", fmt.Errorf("content of %s differs from what has been written", key)
+	}
+
+	if _, err = s3Client.DeleteObject((&s3.DeleteObjectInput{}).
+		SetBucket(bucketName).
+		SetKey(key),
+	); err != nil {
+		return "", fmt.Errorf("could not delete %s: %s", key, err.Error())
+	}
+	deleted = true
+	return fmt.Sprintf("wrote, read and deleted %s (%d bytes)", key, len(content)), nil
+}
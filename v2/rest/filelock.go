@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 30 * time.Second
+)
+
+// lockFile acquires an exclusive advisory lock on the passed file, by locking a sibling .lock file with the locking
+// primitive of the operating system. It waits until the lock is released by other processes, and returns a function
+// that releases it. The system releases the lock when the process exits, so that a crashed process never leaves
+// a stale lock behind. The .lock file itself is kept: removing it would let another process lock a new file
+// while the old one is still locked.
+func lockFile(filePath string) (func(), error) {
+	lockPath := filePath + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not lock %s: %s", filePath, err.Error())
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not lock %s: %s", filePath, err.Error())
+		}
+		if locked {
+			return func() {
+				_ = unlock(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("could not lock %s: another process has held the lock for more than %s", filePath, lockTimeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// writeFilePrivate atomically replaces the content of a file that is only readable by the current user:
+// data is first written to a temporary file in the same folder, that is then renamed.
+// Readers thus never see a partially written file.
+func writeFilePrivate(filePath string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// Does nothing once the file has been renamed
+		_ = os.Remove(tmpPath)
+	}()
+	if err = tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package rest
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// tryLock tries to acquire an exclusive lock on the file without waiting, it returns false if another process holds it.
+func tryLock(f *os.File) (bool, error) {
+	for {
+		err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+		switch err {
+		case nil:
+			return true, nil
+		case unix.EINTR:
+			continue
+		case unix.EWOULDBLOCK:
+			return false, nil
+		}
+		return false, err
+	}
+}
+
+func unlock(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
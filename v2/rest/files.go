@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -21,36 +22,76 @@ import (
 )
 
 func GetS3Client() (*s3.S3, string, error) {
-	DefaultConfig.CustomHeaders = map[string]string{"User-Agent": common.AppName + "/" + common.Version}
+	addUserAgent(DefaultConfig)
+	httpClient, e := newProfileClient(DefaultConfig, nil)
+	if e != nil {
+		return nil, "", e
+	}
 	s3Config := getS3ConfigFromSdkConfig(DefaultConfig)
 	bucketName := s3Config.Bucket
-	s3Client, e := s3transport.GetClient(&DefaultConfig.SdkConfig, &s3Config)
+	sdkConfig := DefaultConfig.sdkConfig()
+	s3Client, e := s3transport.GetClient(&sdkConfig, &s3Config)
 	if e != nil {
 		return nil, "", e
 	}
 	s3Client.Config.S3DisableContentMD5Validation = aws.Bool(true)
+	// Requests are signed with the credentials of the session: the client only brings the transport settings of the profile
+	s3Client.Config.HTTPClient = httpClient
+	if DefaultConfig.AuthType != common.ClientAuthType {
+		// Sign each request with the latest token, that may be refreshed in the background during long transfers
+		s3Client.Config.Credentials = credentials.NewCredentials(&tokenCredentials{c: DefaultConfig, secret: s3Config.ApiSecret})
+	}
 	return s3Client, bucketName, e
 }
 
+// tokenCredentials provides the credentials of the S3 gateway: the current token of the config and the gateway secret.
+type tokenCredentials struct {
+	c      *CecConfig
+	secret string
+}
+
+func (t *tokenCredentials) Retrieve() (credentials.Value, error) {
+	idToken, _, _ := t.c.tokens()
+	if idToken == "" {
+		return credentials.Value{}, fmt.Errorf("no token is defined for %s", t.c.Url)
+	}
+	return credentials.Value{AccessKeyID: idToken, SecretAccessKey: t.secret, ProviderName: "CellsToken"}, nil
+}
+
+// IsExpired always returns true, so that the latest token is retrieved for each request.
+func (t *tokenCredentials) IsExpired() bool {
+	return true
+}
+
 func GetFile(pathToFile string) (io.Reader, int, error) {
+	return GetFileVersion(pathToFile, "")
+}
+
+// GetFileVersion retrieves the content of a given version of a file. If versionID is empty, the current version is returned.
+func GetFileVersion(pathToFile, versionID string) (io.Reader, int, error) {
 
 	s3Client, bucketName, e := GetS3Client()
 	if e != nil {
 		return nil, 0, e
 	}
-	hO, err := s3Client.HeadObject((&s3.HeadObjectInput{}).
+	headInput := (&s3.HeadObjectInput{}).
 		SetBucket(bucketName).
-		SetKey(pathToFile),
-	)
+		SetKey(pathToFile)
+	getInput := (&s3.GetObjectInput{}).
+		SetBucket(bucketName).
+		SetKey(pathToFile)
+	if versionID != "" {
+		headInput.SetVersionId(versionID)
+		getInput.SetVersionId(versionID)
+	}
+
+	hO, err := s3Client.HeadObject(headInput)
 	if err != nil {
 		return nil, 0, err
 	}
 	size := int(*hO.ContentLength)
 
-	obj, err := s3Client.GetObject((&s3.GetObjectInput{}).
-		SetBucket(bucketName).
-		SetKey(pathToFile),
-	)
+	obj, err := s3Client.GetObject(getInput)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -105,48 +146,51 @@ func PutFile(pathToFile string, content io.ReadSeeker, checkExists bool, errChan
 }
 
 func StatNode(pathToFile string) (*models.TreeNode, bool) {
+	n, ok, _ := LookupNode(pathToFile)
+	return n, ok
+}
 
+// LookupNode retrieves the node at the passed path. Unlike StatNode, it only returns false without error
+// when the node does not exist: any other failure, e.g. a network or an authorization error, is returned.
+func LookupNode(pathToFile string) (*models.TreeNode, bool, error) {
 	ctx, client, e := GetApiClient()
 	if e != nil {
-		return nil, false
+		return nil, false, e
 	}
 	params := &tree_service.HeadNodeParams{}
 	params.SetNode(pathToFile)
 	params.SetContext(ctx)
 	resp, err := client.TreeService.HeadNode(params)
-	if err == nil && resp.Payload.Node != nil {
-		return resp.Payload.Node, true
-	} else {
-		return nil, false
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("could not stat %s: %s", pathToFile, err.Error())
 	}
-
+	if resp.Payload.Node == nil {
+		return nil, false, nil
+	}
+	return resp.Payload.Node, true, nil
 }
 
 func ListNodesPath(path string) ([]string, error) {
-	_, client, err := GetApiClient()
-	if err != nil {
-		return nil, err
-	}
-	params := tree_service.NewBulkStatNodesParams()
-	params.Body = &models.RestGetBulkMetaRequest{
-		Limit:     100,
-		NodePaths: []string{path},
-	}
-	res, e := client.TreeService.BulkStatNodes(params)
+	nn, e := bulkStatAll(path)
 	if e != nil {
 		return nil, e
 	}
 	var nodes []string
-	if len(res.Payload.Nodes) == 0 {
+	if len(nn) == 0 {
 		return nil, nil
 	}
-	for _, node := range res.Payload.Nodes {
+	for _, node := range nn {
 		nodes = append(nodes, node.Path)
 	}
 	return nodes, nil
 }
 
-func DeleteNode(paths []string) (jobUUIDs []string, e error) {
+// DeleteNode launches the jobs that remove the passed nodes. Nodes are moved to the recycle bin
+// of their workspace, unless removePermanently is true or they are already in the recycle bin.
+func DeleteNode(paths []string, removePermanently bool) (jobUUIDs []string, e error) {
 	if len(paths) == 0 {
 		e = fmt.Errorf("no paths found to delete")
 		return
@@ -163,7 +207,8 @@ func DeleteNode(paths []string) (jobUUIDs []string, e error) {
 
 	params := tree_service.NewDeleteNodesParams()
 	params.Body = &models.RestDeleteNodesRequest{
-		Nodes: nn,
+		Nodes:             nn,
+		RemovePermanently: removePermanently,
 	}
 	res, err := client.TreeService.DeleteNodes(params)
 	if err != nil {
@@ -178,20 +223,7 @@ func DeleteNode(paths []string) (jobUUIDs []string, e error) {
 }
 
 func GetBulkMetaNode(path string) ([]*models.TreeNode, error) {
-	_, client, err := GetApiClient()
-	if err != nil {
-		return nil, err
-	}
-	params := tree_service.NewBulkStatNodesParams()
-	params.Body = &models.RestGetBulkMetaRequest{
-		Limit:     100,
-		NodePaths: []string{path},
-	}
-	res, e := client.TreeService.BulkStatNodes(params)
-	if e != nil {
-		return nil, e
-	}
-	return res.Payload.Nodes, nil
+	return bulkStatAll(path)
 }
 
 func TreeCreateNodes(nodes []*models.TreeNode) error {
@@ -230,8 +262,10 @@ func uploadManager(path string, content io.ReadSeeker, computeMD5 bool, errChan
 		u.PartSize = 50 * 1024 * 1024
 		u.Concurrency = 3
 		u.RequestOptions = []request.Option{func(r *request.Request) {
-			// We call log.fatal inside the method if there is an error, no need to manage that here.
-			RefreshAndStoreIfRequired(DefaultConfig)
+			// The request fails with a meaningful error rather than an authorization error
+			if _, err := RefreshAndStoreIfRequired(DefaultConfig); err != nil {
+				r.Error = err
+			}
 
 			// s3Config := getS3ConfigFromSdkConfig(DefaultConfig)
 			// apiKey, _ := oidc.RetrieveToken(&DefaultConfig.SdkConfig)
@@ -0,0 +1,483 @@
+package rest
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/tree_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// listPageSize is the number of nodes we retrieve at each call when listing a remote folder.
+const listPageSize = 100
+
+// globSegment is the compiled form of one segment of a remote path pattern.
+type globSegment struct {
+	literal   string
+	re        *regexp.Regexp
+	recursive bool
+}
+
+// HasGlob returns true if the passed path contains at least one unescaped wild char,
+// that is one of '*', '?', '[' or '{'. A '[' that is not closed is a literal char, as with a POSIX shell.
+func HasGlob(p string) bool {
+	rr := []rune(p)
+	escaped := false
+	for i, r := range rr {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case '*', '?', '{':
+			return true
+		case '[':
+			if classEnd(rr, i) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// classEnd returns the index of the ']' that closes the character class opened at index i, or -1 if it is not closed.
+// A ']' right after the opening bracket, or after its negation, is part of the class.
+func classEnd(rr []rune, i int) int {
+	end := i + 1
+	if end < len(rr) && (rr[end] == '!' || rr[end] == '^') {
+		end++
+	}
+	if end < len(rr) && rr[end] == ']' {
+		end++
+	}
+	for end < len(rr) && rr[end] != ']' {
+		if rr[end] == '/' {
+			return -1
+		}
+		end++
+	}
+	if end >= len(rr) {
+		return -1
+	}
+	return end
+}
+
+// MatchGlob checks if the passed remote path matches the pattern. Both are expected to be
+// relative to the root of the server, that is to start with a workspace slug.
+func MatchGlob(pattern, nodePath string) (bool, error) {
+	segs, err := parseGlob(pattern)
+	if err != nil {
+		return false, err
+	}
+	return matchSegments(segs, splitPath(nodePath)), nil
+}
+
+// ExpandGlob finds all nodes on the server whose path matches the passed pattern.
+// Supported wild chars are:
+//   - '*' matches any sequence of characters inside a path segment,
+//   - '?' matches any single character,
+//   - '[abc]', '[a-z]' and '[!abc]' match one character of (or not in) the class,
+//   - '{a,b}' matches one of the comma-separated alternatives,
+//   - '**' as a full segment matches zero or more folders.
+//
+// Wild chars can be escaped with a backslash. As with a POSIX shell, names starting with a dot
+// are only matched if the corresponding pattern segment also starts with a dot. The recycle bins and
+// their content are only matched when the pattern explicitly contains the recycle_bin segment.
+// Matching is done client side, by listing the relevant folders page per page.
+// If the pattern has no wild char, we simply stat the corresponding node.
+func ExpandGlob(pattern string) ([]*models.TreeNode, error) {
+	segs, err := parseGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the literal prefix directly
+	base, i := literalPrefix(segs)
+	if i == len(segs) {
+		n, ok, err := LookupNode(base)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []*models.TreeNode{n}, nil
+	}
+	if base != "" {
+		n, ok, err := LookupNode(base)
+		if err != nil || !ok || !isFolder(n) {
+			return nil, err
+		}
+	}
+
+	g := &globber{seen: make(map[string]bool)}
+	if err = g.walk(base, segs[i:]); err != nil {
+		return nil, err
+	}
+	sort.Slice(g.found, func(i, j int) bool {
+		return g.found[i].Path < g.found[j].Path
+	})
+	return g.found, nil
+}
+
+// ExpandGlobPaths returns the paths of the nodes found by ExpandGlob, without the nodes that are inside
+// a folder that is also found: it is meant for commands that process folders recursively.
+func ExpandGlobPaths(pattern string) ([]string, error) {
+	nodes, err := ExpandGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, n := range RemoveNestedNodes(nodes) {
+		paths = append(paths, strings.Trim(n.Path, "/"))
+	}
+	return paths, nil
+}
+
+// RemoveNestedNodes drops the nodes that are inside a folder of the list, so that commands that
+// process folders recursively do not process their content twice. The order of the list is kept.
+func RemoveNestedNodes(nodes []*models.TreeNode) []*models.TreeNode {
+	folders := make(map[string]bool)
+	for _, n := range nodes {
+		if isFolder(n) {
+			folders[strings.Trim(n.Path, "/")] = true
+		}
+	}
+	var topmost []*models.TreeNode
+	for _, n := range nodes {
+		if !hasAncestorIn(strings.Trim(n.Path, "/"), folders) {
+			topmost = append(topmost, n)
+		}
+	}
+	return topmost
+}
+
+func hasAncestorIn(p string, folders map[string]bool) bool {
+	for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if folders[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobBase returns the leading segments of the pattern that have no wild char, e.g. common-files/folder
+// for common-files/folder/**/*.txt. The paths found by ExpandGlob are all inside this base.
+func GlobBase(pattern string) (string, error) {
+	segs, err := parseGlob(pattern)
+	if err != nil {
+		return "", err
+	}
+	base, _ := literalPrefix(segs)
+	return base, nil
+}
+
+// literalPrefix joins the leading literal segments, and returns the index of the first segment with a wild char.
+func literalPrefix(segs []globSegment) (string, int) {
+	var prefix []string
+	i := 0
+	for ; i < len(segs) && segs[i].re == nil && !segs[i].recursive; i++ {
+		prefix = append(prefix, segs[i].literal)
+	}
+	return path.Join(prefix...), i
+}
+
+type globber struct {
+	found []*models.TreeNode
+	seen  map[string]bool
+}
+
+func (g *globber) add(n *models.TreeNode) {
+	p := strings.Trim(n.Path, "/")
+	if g.seen[p] {
+		return
+	}
+	g.seen[p] = true
+	g.found = append(g.found, n)
+}
+
+func (g *globber) walk(dir string, segs []globSegment) error {
+	if len(segs) == 0 {
+		return nil
+	}
+	seg := segs[0]
+	last := len(segs) == 1
+
+	if seg.recursive {
+		// '**' matches zero folder: try to match the remaining segments at this level
+		if !last {
+			if err := g.walk(dir, segs[1:]); err != nil {
+				return err
+			}
+		}
+		children, err := listChildren(dir)
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			if !seg.matches(path.Base(c.Path)) {
+				continue
+			}
+			if last {
+				g.add(c)
+			}
+			if isFolder(c) {
+				if err = g.walk(strings.Trim(c.Path, "/"), segs); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if seg.re == nil {
+		// Literal segment after a wild char: no need to list the folder
+		n, ok, err := LookupNode(path.Join(dir, seg.literal))
+		if err != nil || !ok {
+			return err
+		}
+		if last {
+			g.add(n)
+		} else if isFolder(n) {
+			return g.walk(path.Join(dir, seg.literal), segs[1:])
+		}
+		return nil
+	}
+
+	children, err := listChildren(dir)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if !seg.matches(path.Base(c.Path)) {
+			continue
+		}
+		if last {
+			g.add(c)
+		} else if isFolder(c) {
+			if err = g.walk(strings.Trim(c.Path, "/"), segs[1:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s globSegment) matches(name string) bool {
+	if s.re == nil && !s.recursive {
+		return s.literal == name
+	}
+	// Wild chars never match the recycle bins: they must be explicitly targeted
+	if name == RecycleBinName {
+		return false
+	}
+	if s.recursive {
+		return !strings.HasPrefix(name, ".")
+	}
+	if strings.HasPrefix(name, ".") && !strings.HasPrefix(s.literal, ".") {
+		return false
+	}
+	return s.re.MatchString(name)
+}
+
+func matchSegments(segs []globSegment, parts []string) bool {
+	if len(segs) == 0 {
+		return len(parts) == 0
+	}
+	if segs[0].recursive {
+		for i := 0; i <= len(parts); i++ {
+			if i > 0 && !segs[0].matches(parts[i-1]) {
+				return false
+			}
+			if len(segs) == 1 && i == 0 {
+				// trailing '**' matches descendants, not the folder itself
+				continue
+			}
+			if matchSegments(segs[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 || !segs[0].matches(parts[0]) {
+		return false
+	}
+	return matchSegments(segs[1:], parts[1:])
+}
+
+// listChildren retrieves all direct children of the passed remote folder.
+func listChildren(dir string) ([]*models.TreeNode, error) {
+	if dir == "" {
+		return bulkStatAll("/*")
+	}
+	return bulkStatAll(path.Join(dir, "*"))
+}
+
+// bulkStatAll calls the BulkStatNodes endpoint for the passed node path,
+// walking through the result pages until all nodes have been retrieved.
+func bulkStatAll(nodePath string) ([]*models.TreeNode, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*models.TreeNode
+	var offset int32
+	for {
+		params := tree_service.NewBulkStatNodesParams()
+		params.SetContext(ctx)
+		params.Body = &models.RestGetBulkMetaRequest{
+			Limit:     listPageSize,
+			Offset:    offset,
+			NodePaths: []string{nodePath},
+		}
+		res, e := client.TreeService.BulkStatNodes(params)
+		if e != nil {
+			return nil, e
+		}
+		nodes = append(nodes, res.Payload.Nodes...)
+		nb := int32(len(res.Payload.Nodes))
+		offset += nb
+		if nb < listPageSize {
+			break
+		}
+		if pg := res.Payload.Pagination; pg != nil && pg.Total > 0 && offset >= pg.Total {
+			break
+		}
+	}
+	return nodes, nil
+}
+
+func isFolder(n *models.TreeNode) bool {
+	return n.Type != nil && *n.Type == models.TreeNodeTypeCOLLECTION
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func parseGlob(pattern string) ([]globSegment, error) {
+	var segs []globSegment
+	for _, part := range splitPath(pattern) {
+		switch {
+		case part == "**":
+			segs = append(segs, globSegment{literal: part, recursive: true})
+		case HasGlob(part):
+			expr, err := globToRegexp(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err.Error())
+			}
+			re, err := regexp.Compile("^" + expr + "$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err.Error())
+			}
+			segs = append(segs, globSegment{literal: part, re: re})
+		default:
+			segs = append(segs, globSegment{literal: unescapeGlob(part)})
+		}
+	}
+	return segs, nil
+}
+
+// globToRegexp translates a single path segment pattern into the equivalent regular expression.
+func globToRegexp(seg string) (string, error) {
+	var sb strings.Builder
+	rr := []rune(seg)
+	for i := 0; i < len(rr); i++ {
+		switch c := rr[i]; c {
+		case '\\':
+			if i+1 < len(rr) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(rr[i])))
+			} else {
+				sb.WriteString(regexp.QuoteMeta("\\"))
+			}
+		case '*':
+			sb.WriteString(`[^/]*`)
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '[':
+			end := classEnd(rr, i)
+			if end < 0 {
+				// Not a character class
+				sb.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := rr[i+1 : end]
+			sb.WriteString("[")
+			if len(class) > 0 && (class[0] == '!' || class[0] == '^') {
+				sb.WriteString("^")
+				class = class[1:]
+			}
+			for _, cc := range class {
+				if cc == '\\' || cc == '[' || cc == ']' || cc == '^' {
+					sb.WriteRune('\\')
+				}
+				sb.WriteRune(cc)
+			}
+			sb.WriteString("]")
+			i = end
+		case '{':
+			depth := 1
+			end := i + 1
+			var alternatives []string
+			start := end
+			for ; end < len(rr) && depth > 0; end++ {
+				switch rr[end] {
+				case '\\':
+					end++
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						alternatives = append(alternatives, string(rr[start:end]))
+					}
+				case ',':
+					if depth == 1 {
+						alternatives = append(alternatives, string(rr[start:end]))
+						start = end + 1
+					}
+				}
+			}
+			if depth > 0 {
+				return "", fmt.Errorf("unterminated alternative")
+			}
+			var exprs []string
+			for _, alt := range alternatives {
+				expr, err := globToRegexp(alt)
+				if err != nil {
+					return "", err
+				}
+				exprs = append(exprs, expr)
+			}
+			sb.WriteString("(?:" + strings.Join(exprs, "|") + ")")
+			i = end - 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String(), nil
+}
+
+func unescapeGlob(seg string) string {
+	if !strings.Contains(seg, "\\") {
+		return seg
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, r := range seg {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
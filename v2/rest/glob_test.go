@@ -0,0 +1,244 @@
+package rest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+func TestHasGlob(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"common-files/folder/file.txt", false},
+		{"common-files/*.txt", true},
+		{"common-files/file?.txt", true},
+		{"common-files/[ab].txt", true},
+		{"common-files/{a,b}.txt", true},
+		{"common-files/**", true},
+		{`common-files/\*.txt`, false},
+		{`common-files/\[draft\].txt`, false},
+		{`common-files/\\*.txt`, true},
+		// An unterminated bracket is a literal char
+		{"common-files/[draft.txt", false},
+		{"common-files/[a/b]", false},
+		{"common-files/[draft*.txt", true},
+	}
+	for _, tt := range tests {
+		if got := HasGlob(tt.path); got != tt.want {
+			t.Errorf("HasGlob(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		seg     string
+		want    string
+		wantErr bool
+	}{
+		{seg: "file.txt", want: `file\.txt`},
+		{seg: "*.txt", want: `[^/]*\.txt`},
+		{seg: "file?.txt", want: `file[^/]\.txt`},
+		{seg: "[abc].txt", want: `[abc]\.txt`},
+		{seg: "[!abc].txt", want: `[^abc]\.txt`},
+		{seg: "[^a-z]", want: `[^a-z]`},
+		{seg: "[]a]", want: `[\]a]`},
+		{seg: "{jpg,png}", want: `(?:jpg|png)`},
+		{seg: "*.{jp*g,png}", want: `[^/]*\.(?:jp[^/]*g|png)`},
+		{seg: `\*.txt`, want: `\*\.txt`},
+		{seg: `trailing\`, want: `trailing\\`},
+		{seg: "[abc", want: `\[abc`},
+		{seg: "[abc*", want: `\[abc[^/]*`},
+		{seg: "{a,b", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := globToRegexp(tt.seg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("globToRegexp(%q) error = %v, wantErr %v", tt.seg, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", tt.seg, got, tt.want)
+		}
+	}
+}
+
+func TestParseGlob(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		literals  []string
+		wild      []bool
+		recursive []bool
+		wantErr   bool
+	}{
+		{
+			pattern:   "common-files/folder/file.txt",
+			literals:  []string{"common-files", "folder", "file.txt"},
+			wild:      []bool{false, false, false},
+			recursive: []bool{false, false, false},
+		},
+		{
+			pattern:   "/common-files/**/*.txt/",
+			literals:  []string{"common-files", "**", "*.txt"},
+			wild:      []bool{false, false, true},
+			recursive: []bool{false, true, false},
+		},
+		{
+			// '**' is only recursive as a full segment
+			pattern:   "common-files/a**b",
+			literals:  []string{"common-files", "a**b"},
+			wild:      []bool{false, true},
+			recursive: []bool{false, false},
+		},
+		{
+			pattern:   `common-files/\[draft\].txt`,
+			literals:  []string{"common-files", "[draft].txt"},
+			wild:      []bool{false, false},
+			recursive: []bool{false, false},
+		},
+		{
+			pattern:   "common-files/[abc",
+			literals:  []string{"common-files", "[abc"},
+			wild:      []bool{false, false},
+			recursive: []bool{false, false},
+		},
+		{pattern: "common-files/{a,b", wantErr: true},
+	}
+	for _, tt := range tests {
+		segs, err := parseGlob(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGlob(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		var literals []string
+		var wild, recursive []bool
+		for _, s := range segs {
+			literals = append(literals, s.literal)
+			wild = append(wild, s.re != nil)
+			recursive = append(recursive, s.recursive)
+		}
+		if !reflect.DeepEqual(literals, tt.literals) || !reflect.DeepEqual(wild, tt.wild) || !reflect.DeepEqual(recursive, tt.recursive) {
+			t.Errorf("parseGlob(%q) = %v %v %v, want %v %v %v", tt.pattern, literals, wild, recursive, tt.literals, tt.wild, tt.recursive)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"ws/*.txt", "ws/file.txt", true},
+		{"ws/*.txt", "ws/folder/file.txt", false},
+		{"ws/file?.txt", "ws/file1.txt", true},
+		{"ws/file?.txt", "ws/file10.txt", false},
+		{"ws/[a-c]*", "ws/beta", true},
+		{"ws/[!a-c]*", "ws/beta", false},
+		{"ws/*.{jpg,png}", "ws/cat.png", true},
+		{"ws/*.{jpg,png}", "ws/cat.gif", false},
+		{`ws/\*.txt`, "ws/*.txt", true},
+		{`ws/\*.txt`, "ws/a.txt", false},
+
+		// '**' matches zero or more folders
+		{"ws/**/*.txt", "ws/file.txt", true},
+		{"ws/**/*.txt", "ws/a/file.txt", true},
+		{"ws/**/*.txt", "ws/a/b/c/file.txt", true},
+		{"ws/**/b/*.txt", "ws/b/file.txt", true},
+		{"ws/**/b/*.txt", "ws/a/b/file.txt", true},
+		{"ws/**/b/*.txt", "ws/a/c/file.txt", false},
+
+		// A trailing '**' matches the descendants, not the folder itself
+		{"ws/folder/**", "ws/folder", false},
+		{"ws/folder/**", "ws/folder/a", true},
+		{"ws/folder/**", "ws/folder/a/b", true},
+
+		// Names starting with a dot are only matched explicitly
+		{"ws/*", "ws/.hidden", false},
+		{"ws/.*", "ws/.hidden", true},
+		{"ws/**/*.txt", "ws/.git/file.txt", false},
+		{"ws/**", "ws/a/.hidden", false},
+
+		// Recycle bins are only matched explicitly
+		{"ws/*", "ws/recycle_bin", false},
+		{"ws/**", "ws/recycle_bin/file.txt", false},
+		{"ws/*/file.txt", "ws/recycle_bin/file.txt", false},
+		{"*/*/*.txt", "ws/recycle_bin/file.txt", false},
+		{"ws/recycle_bin/*.txt", "ws/recycle_bin/file.txt", true},
+		{"ws/**/recycle_bin/*", "ws/recycle_bin/file.txt", true},
+
+		// An unterminated bracket only matches itself
+		{"ws/[draft", "ws/[draft", true},
+		{"ws/[draft*", "ws/[draft-1", true},
+		{"ws/[draft*", "ws/draft-1", false},
+	}
+	for _, tt := range tests {
+		got, err := MatchGlob(tt.pattern, tt.path)
+		if err != nil {
+			t.Errorf("MatchGlob(%q, %q) unexpected error: %v", tt.pattern, tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGlobBase(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"ws/folder/**/*.txt", "ws/folder"},
+		{"ws/*/file.txt", "ws"},
+		{"*/file.txt", ""},
+		{`ws/\[draft\]/*`, "ws/[draft]"},
+		{"ws/folder/file.txt", "ws/folder/file.txt"},
+	}
+	for _, tt := range tests {
+		got, err := GlobBase(tt.pattern)
+		if err != nil {
+			t.Errorf("GlobBase(%q) unexpected error: %v", tt.pattern, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GlobBase(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveNestedNodes(t *testing.T) {
+	folder := func(p string) *models.TreeNode {
+		return &models.TreeNode{Path: p, Type: models.NewTreeNodeType(models.TreeNodeTypeCOLLECTION)}
+	}
+	file := func(p string) *models.TreeNode {
+		return &models.TreeNode{Path: p, Type: models.NewTreeNodeType(models.TreeNodeTypeLEAF)}
+	}
+	nodes := []*models.TreeNode{
+		folder("ws/a"),
+		file("ws/a/x.txt"),
+		folder("ws/a/b"),
+		file("ws/a/b/y.txt"),
+		// Shares a prefix with ws/a but is not inside it
+		file("ws/a-b.txt"),
+		folder("/ws/c/"),
+		file("ws/c/d/z.txt"),
+		file("ws/e.txt"),
+		// A file cannot contain other nodes
+		file("ws/e.txt/f"),
+	}
+	var got []string
+	for _, n := range RemoveNestedNodes(nodes) {
+		got = append(got, n.Path)
+	}
+	want := []string{"ws/a", "ws/a-b.txt", "/ws/c/", "ws/e.txt", "ws/e.txt/f"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RemoveNestedNodes() = %v, want %v", got, want)
+	}
+}
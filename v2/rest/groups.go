@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/user_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// ListGroups retrieves the groups that are under the passed parent group, or all its descendants if recursive is true.
+// Groups are sorted by full path.
+func ListGroups(parentPath string, recursive bool) ([]*models.IdmUser, error) {
+	parentPath = "/" + strings.Trim(parentPath, "/")
+	res, err := searchUsers(&models.IdmUserSingleQuery{
+		GroupPath: parentPath,
+		Recursive: recursive,
+		NodeType:  models.NewIdmNodeType(models.IdmNodeTypeGROUP),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list groups under %s: %s", parentPath, err.Error())
+	}
+	var groups []*models.IdmUser
+	for _, g := range res.Groups {
+		// Do not return the parent group itself
+		if GroupFullPath(g) != parentPath {
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return GroupFullPath(groups[i]) < GroupFullPath(groups[j])
+	})
+	return groups, nil
+}
+
+// ListGroupMembers retrieves the users of the passed group, including the users of its sub-groups if recursive is true.
+func ListGroupMembers(groupPath string, recursive bool) ([]*models.IdmUser, error) {
+	groupPath = "/" + strings.Trim(groupPath, "/")
+	res, err := searchUsers(&models.IdmUserSingleQuery{
+		GroupPath: groupPath,
+		Recursive: recursive,
+		NodeType:  models.NewIdmNodeType(models.IdmNodeTypeUSER),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list members of %s: %s", groupPath, err.Error())
+	}
+	sort.Slice(res.Users, func(i, j int) bool {
+		return res.Users[i].Login < res.Users[j].Login
+	})
+	return res.Users, nil
+}
+
+// CreateGroup creates a group at the passed full path. The parent group must exist.
+func CreateGroup(groupPath, displayName string) (*models.IdmUser, error) {
+	groupPath = "/" + strings.Trim(groupPath, "/")
+	if groupPath == "/" {
+		return nil, fmt.Errorf("cannot create the root group")
+	}
+	parent, label := path.Split(groupPath)
+	if parent != "/" {
+		if _, err := FindGroup(parent); err != nil {
+			return nil, fmt.Errorf("parent group %s does not exist", parent)
+		}
+	}
+	if _, err := FindGroup(groupPath); err == nil {
+		return nil, fmt.Errorf("group %s already exists", groupPath)
+	}
+	g := &models.IdmUser{
+		IsGroup:    true,
+		GroupLabel: label,
+		GroupPath:  path.Clean(parent),
+		Attributes: map[string]string{},
+	}
+	if displayName != "" {
+		g.Attributes[UserAttrDisplayName] = displayName
+	}
+	return saveGroup(g, groupPath)
+}
+
+// MoveGroup moves a group (with its users and sub-groups) under another parent group.
+func MoveGroup(groupPath, newParentPath string) (*models.IdmUser, error) {
+	g, err := FindGroup(groupPath)
+	if err != nil {
+		return nil, err
+	}
+	newParentPath = "/" + strings.Trim(newParentPath, "/")
+	if newParentPath != "/" {
+		if _, err = FindGroup(newParentPath); err != nil {
+			return nil, fmt.Errorf("target group %s does not exist", newParentPath)
+		}
+	}
+	oldPath := GroupFullPath(g)
+	if newParentPath == oldPath || strings.HasPrefix(newParentPath, oldPath+"/") {
+		return nil, fmt.Errorf("cannot move %s inside itself", oldPath)
+	}
+	g.GroupPath = newParentPath
+	return saveGroup(g, path.Join(newParentPath, g.GroupLabel))
+}
+
+// DeleteGroup removes a group. Beware that its users and sub-groups are also removed.
+func DeleteGroup(groupPath string) error {
+	g, err := FindGroup(groupPath)
+	if err != nil {
+		return err
+	}
+	return deleteIdmNode(GroupFullPath(g))
+}
+
+// SetUserGroup moves a user to the passed group: in Cells, a user belongs to exactly one group.
+func SetUserGroup(login, groupPath string) error {
+	groupPath = "/" + strings.Trim(groupPath, "/")
+	if groupPath != "/" {
+		if _, err := FindGroup(groupPath); err != nil {
+			return err
+		}
+	}
+	_, err := UpdateUser(&UserDefinition{Login: login, GroupPath: groupPath})
+	return err
+}
+
+func saveGroup(g *models.IdmUser, fullPath string) (*models.IdmUser, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &user_service.PutUserParams{Login: strings.TrimLeft(fullPath, "/"), Body: g, Context: ctx}
+	res, err := client.UserService.PutUser(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not save group %s: %s", fullPath, err.Error())
+	}
+	return res.Payload, nil
+}
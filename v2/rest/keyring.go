@@ -2,6 +2,7 @@ package rest
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/zalando/go-keyring"
@@ -10,31 +11,105 @@ import (
 )
 
 // NoKeyringMsg warns end user when no keyring is found
-const NoKeyringMsg = "Could not access local keyring: sensitive information like token or password will end up stored in clear text in the client machine."
+const NoKeyringMsg = "Could not access local keyring: sensitive information like token or password will end up stored in clear text in the client machine. " +
+	"Set CEC_SECRET_BACKEND=file and CEC_SECRET_KEY to store them in an encrypted file instead."
 
-func getKeyringServiceName() string {
+// Names of the backends that can store sensitive information.
+const (
+	SecretBackendKeyring = "keyring"
+	SecretBackendFile    = "file"
+	SecretBackendCommand = "command"
+)
+
+var (
+	// SecretBackend is the backend that is used to store the sensitive information of new profiles.
+	// If empty, the OS keyring is used, or the encrypted file if the keyring is not available and a secret key is defined.
+	SecretBackend string
+	// SecretCommand is the external command that is used by the command backend of new profiles, e.g: pass or vault.
+	// It is then stored with each profile, and only used for the profiles that have no command of their own.
+	SecretCommand string
+)
+
+// SecretStore stores sensitive information like tokens and passwords.
+type SecretStore interface {
+	Get(service, key string) (string, error)
+	Set(service, key, value string) error
+	Delete(service, key string) error
+}
+
+// osKeyring stores secrets in the keyring of the operating system.
+type osKeyring struct{}
+
+func (osKeyring) Get(service, key string) (string, error) { return keyring.Get(service, key) }
+func (osKeyring) Set(service, key, value string) error    { return keyring.Set(service, key, value) }
+func (osKeyring) Delete(service, key string) error        { return keyring.Delete(service, key) }
+
+// GetSecretStore returns the store that implements the backend with the passed name. An empty name designates the OS keyring.
+// The command is only used by the command backend: if empty, the SecretCommand variable is used.
+func GetSecretStore(backend, command string) (SecretStore, error) {
+	switch backend {
+	case "", SecretBackendKeyring:
+		return osKeyring{}, nil
+	case SecretBackendFile:
+		return defaultFileStore, nil
+	case SecretBackendCommand:
+		if command == "" {
+			command = SecretCommand
+		}
+		if command == "" {
+			return nil, fmt.Errorf("no command defined for the command secret backend, please set CEC_SECRET_COMMAND")
+		}
+		return &commandStore{command: command}, nil
+	}
+	return nil, fmt.Errorf("unknown secret backend %s, expected one of %s, %s or %s", backend, SecretBackendKeyring, SecretBackendFile, SecretBackendCommand)
+}
+
+// KeyringServiceName is the name of the service under which secrets are stored.
+func KeyringServiceName() string {
 	return "com.pydio." + common.AppName
 }
 
-// ConfigToKeyring stores sensitive information in local keyring if any and removes it from current SDK config.
+// ConfigToKeyring stores sensitive information in the secret backend of the config and removes it from current SDK config.
+// For new configs, the backend is chosen with the SecretBackend variable: if none is defined and the OS keyring is not available,
+// the encrypted file backend is used when a secret key has been provided.
 func ConfigToKeyring(conf *CecConfig) error {
+	if conf.SecretBackend == "" && SecretBackend != "" {
+		conf.SecretBackend = SecretBackend
+	}
+	if conf.SecretBackend == SecretBackendCommand && conf.SecretCommand == "" {
+		// Keep the command with the profile, so that it is still found when another command is used for new profiles
+		conf.SecretCommand = SecretCommand
+	}
+	err := configToStore(conf)
+	if err != nil && conf.SecretBackend == "" && os.Getenv(SecretKeyEnv) != "" {
+		conf.SecretBackend = SecretBackendFile
+		err = configToStore(conf)
+	}
+	return err
+}
+
+func configToStore(conf *CecConfig) error {
+	store, err := GetSecretStore(conf.SecretBackend, conf.SecretCommand)
+	if err != nil {
+		return err
+	}
 
 	currKey := key(conf.Url, conf.User)
 	switch conf.AuthType {
 	case common.PatType:
-		if e := keyring.Set(getKeyringServiceName(), currKey, conf.IdToken); e != nil {
+		if e := store.Set(KeyringServiceName(), currKey, conf.IdToken); e != nil {
 			return e
 		}
 		conf.IdToken = ""
 	case common.OAuthType:
 		value := value(conf.IdToken, conf.RefreshToken)
-		if e := keyring.Set(getKeyringServiceName(), currKey, value); e != nil {
+		if e := store.Set(KeyringServiceName(), currKey, value); e != nil {
 			return e
 		}
 		conf.IdToken = ""
 		conf.RefreshToken = ""
 	case common.ClientAuthType:
-		if e := keyring.Set(getKeyringServiceName(), currKey, conf.Password); e != nil {
+		if e := store.Set(KeyringServiceName(), currKey, conf.Password); e != nil {
 			return e
 		}
 		conf.Password = ""
@@ -42,16 +117,23 @@ func ConfigToKeyring(conf *CecConfig) error {
 	return nil
 }
 
-// ConfigFromKeyring tries to find sensitive info inside local keychain and feed the conf.
+// ConfigFromKeyring tries to find sensitive info inside the secret backend of the config and feed the conf.
 func ConfigFromKeyring(conf *CecConfig) error {
-	value, err := keyring.Get(getKeyringServiceName(), key(conf.Url, conf.User))
+	store, err := GetSecretStore(conf.SecretBackend, conf.SecretCommand)
 	if err != nil {
+		return err
+	}
+	value, err := store.Get(KeyringServiceName(), key(conf.Url, conf.User))
+	if err != nil {
+		if conf.SecretBackend != "" && conf.SecretBackend != SecretBackendKeyring {
+			return err
+		}
 		// Best effort to retrieve legacy conf
 		err = retrieveLegacyKey(conf)
 		if err != nil {
 			return err
 		}
-		value, err = keyring.Get(getKeyringServiceName(), key(conf.Url, conf.User))
+		value, err = keyring.Get(KeyringServiceName(), key(conf.Url, conf.User))
 		if err != nil {
 			return err
 		}
@@ -70,25 +152,27 @@ func ConfigFromKeyring(conf *CecConfig) error {
 	return nil
 }
 
-// CheckKeyring simply tries a write followed by a read in the local keyring and
+// CheckKeyring simply tries a write followed by a read in the secret backend that is currently selected and
 // returns nothing if it works or an error otherwise.
 func CheckKeyring() error {
 
-	fmt.Println("Checking keyring service for", getKeyringServiceName())
-
+	store, err := GetSecretStore(SecretBackend, SecretCommand)
+	if err != nil {
+		return err
+	}
 	testKey := key("https://test.example.com", "john.doe")
 	testValue := "A very complicated value !!#%<{}//\\q"
 
-	if e := keyring.Set(getKeyringServiceName(), testKey, testValue); e != nil {
+	if e := store.Set(KeyringServiceName(), testKey, testValue); e != nil {
 		return e
 	}
 
 	defer func() {
 		// Best effort to remove the test key from the keyring => ignore error
-		_ = keyring.Delete(getKeyringServiceName(), testKey)
+		_ = store.Delete(KeyringServiceName(), testKey)
 	}()
 
-	value, err := keyring.Get(getKeyringServiceName(), testKey)
+	value, err := store.Get(KeyringServiceName(), testKey)
 	if err != nil {
 		return err
 	}
@@ -117,11 +201,15 @@ func splitValue(value string) []string {
 	return strings.Split(value, valueSep)
 }
 
-// ClearKeyring removes sensitive info from the local keychain, if they are present.
+// ClearKeyring removes sensitive info from the secret backend of the config, if they are present.
 func ClearKeyring(c *CecConfig) error {
+	store, err := GetSecretStore(c.SecretBackend, c.SecretCommand)
+	if err != nil {
+		return err
+	}
 	// Best effort to remove known keys from keyring
-	if err := keyring.Delete(getKeyringServiceName(), key(c.Url, c.User)); err != nil {
-		if err.Error() != "secret not found in keyring" {
+	if err := store.Delete(KeyringServiceName(), key(c.Url, c.User)); err != nil {
+		if err != keyring.ErrNotFound && err != errSecretNotFound {
 			return err
 		}
 	}
@@ -130,24 +218,24 @@ func ClearKeyring(c *CecConfig) error {
 
 func retrieveLegacyKey(conf *CecConfig) error {
 	if conf.User != "" && conf.Password == "" { // client auth
-		if value, e := keyring.Get(getKeyringServiceName(), key(conf.Url, "ClientCredentials")); e == nil {
+		if value, e := keyring.Get(KeyringServiceName(), key(conf.Url, "ClientCredentials")); e == nil {
 			parts := splitValue(value)
 			//conf.ClientSecret = parts[0]
 			conf.Password = parts[1]
 			conf.AuthType = common.ClientAuthType
 			// Leave the keyring in a clean state
-			_ = keyring.Delete(getKeyringServiceName(), key(conf.Url, "ClientCredentials"))
+			_ = keyring.Delete(KeyringServiceName(), key(conf.Url, "ClientCredentials"))
 		} else {
 			return e
 		}
 	} else if conf.IdToken == "" && conf.RefreshToken == "" && conf.Password == "" { // oauth
-		if value, e := keyring.Get(getKeyringServiceName(), key(conf.Url, "IdToken")); e == nil {
+		if value, e := keyring.Get(KeyringServiceName(), key(conf.Url, "IdToken")); e == nil {
 			parts := splitValue(value)
 			conf.IdToken = parts[0]
 			conf.RefreshToken = parts[1]
 			conf.AuthType = common.OAuthType
 			RefreshIfRequired(conf)
-			_ = keyring.Delete(getKeyringServiceName(), key(conf.Url, "IdToken"))
+			_ = keyring.Delete(KeyringServiceName(), key(conf.Url, "IdToken"))
 		} else {
 			return e
 		}
@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var unsafeSecretChars = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+// commandStore delegates the storage of secrets to an external command. The well-known pass and vault commands
+// are supported out of the box. Any other command is called as a helper with the following arguments:
+//   - get <name>: print the secret on the standard output, or exit with status 2 if there is no such secret,
+//   - set <name>: store the secret that is passed on the standard input,
+//   - delete <name>: remove the secret.
+type commandStore struct {
+	command string
+}
+
+func (c *commandStore) Get(service, key string) (string, error) {
+	name := secretName(service, key)
+	var out string
+	var err error
+	switch c.tool() {
+	case "pass":
+		out, err = c.run("", "show", name)
+	case "vault":
+		out, err = c.run("", "kv", "get", "-field=value", "secret/"+name)
+	default:
+		out, err = c.run("", "get", name)
+	}
+	if err != nil {
+		if c.isNotFound(err) {
+			return "", errSecretNotFound
+		}
+		return "", err
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+func (c *commandStore) Set(service, key, value string) error {
+	name := secretName(service, key)
+	var err error
+	switch c.tool() {
+	case "pass":
+		_, err = c.run(value, "insert", "--multiline", "--force", name)
+	case "vault":
+		_, err = c.run(value, "kv", "put", "secret/"+name, "value=-")
+	default:
+		_, err = c.run(value, "set", name)
+	}
+	return err
+}
+
+func (c *commandStore) Delete(service, key string) error {
+	if _, err := c.Get(service, key); err != nil {
+		return err
+	}
+	name := secretName(service, key)
+	var err error
+	switch c.tool() {
+	case "pass":
+		_, err = c.run("", "rm", "--force", name)
+	case "vault":
+		_, err = c.run("", "kv", "metadata", "delete", "secret/"+name)
+	default:
+		_, err = c.run("", "delete", name)
+	}
+	return err
+}
+
+// isNotFound checks if a failed command only reports that the secret does not exist.
+func (c *commandStore) isNotFound(err error) bool {
+	ce, ok := err.(*commandError)
+	if !ok {
+		return false
+	}
+	switch c.tool() {
+	case "pass":
+		return ce.exitCode == 1 && strings.Contains(ce.stderr, "is not in the password store")
+	case "vault":
+		return ce.exitCode == 2 && strings.Contains(ce.stderr, "No value found")
+	default:
+		return ce.exitCode == 2
+	}
+}
+
+// tool returns the name of the external command, without its path nor its arguments.
+func (c *commandStore) tool() string {
+	fields := strings.Fields(c.command)
+	if len(fields) == 0 {
+		return ""
+	}
+	parts := strings.Split(fields[0], string(os.PathSeparator))
+	return parts[len(parts)-1]
+}
+
+func (c *commandStore) run(stdin string, args ...string) (string, error) {
+	fields := strings.Fields(c.command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no command defined for the command secret backend")
+	}
+	cmd := exec.Command(fields[0], append(fields[1:], args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		ce := &commandError{command: fields[0] + " " + args[0], stderr: strings.TrimSpace(stderr.String()), err: err, exitCode: -1}
+		if ee, ok := err.(*exec.ExitError); ok {
+			ce.exitCode = ee.ExitCode()
+		}
+		return "", ce
+	}
+	return stdout.String(), nil
+}
+
+// commandError is returned when an external command fails: it keeps the exit status and the error output
+// so that the "not found" answers of the tools can be told apart from actual failures.
+type commandError struct {
+	command  string
+	exitCode int
+	stderr   string
+	err      error
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("%s failed: %s %s", e.command, e.err.Error(), e.stderr)
+}
+
+// secretName builds a name that can be safely used as a path by external tools, e.g: com.pydio.cec/https_files.example.com_alice
+func secretName(service, key string) string {
+	return service + "/" + strings.Trim(unsafeSecretChars.ReplaceAllString(key, "_"), "_")
+}
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+	"golang.org/x/crypto/scrypt"
+)
+
+// SecretKeyEnv is the environment variable that holds the passphrase of the encrypted secret file.
+// If it is not set, the passphrase is prompted when needed.
+const SecretKeyEnv = "CEC_SECRET_KEY"
+
+const secretFileName = "secrets.enc"
+
+// Parameters of the scrypt key derivation, as recommended for interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var errSecretNotFound = errors.New("secret not found in encrypted file")
+
+var defaultFileStore = &fileStore{}
+
+// fileStore stores secrets in a file next to the config file, encrypted with AES-GCM
+// using a key that is derived from a passphrase with scrypt.
+type fileStore struct {
+	sync.Mutex
+	passphrase string
+	secrets    map[string]string
+}
+
+// encryptedFile is the on-disk format of the secret file, and of the secrets of exported profiles.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (f *fileStore) Get(service, key string) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	v, ok := f.secrets[service+keySep+key]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return v, nil
+}
+
+func (f *fileStore) Set(service, key, value string) error {
+	return f.update(func(secrets map[string]string) error {
+		secrets[service+keySep+key] = value
+		return nil
+	})
+}
+
+func (f *fileStore) Delete(service, key string) error {
+	return f.update(func(secrets map[string]string) error {
+		if _, ok := secrets[service+keySep+key]; !ok {
+			return errSecretNotFound
+		}
+		delete(secrets, service+keySep+key)
+		return nil
+	})
+}
+
+// update reloads the secrets while holding a lock on the file, so that changes made by other processes are not lost.
+func (f *fileStore) update(modify func(secrets map[string]string) error) error {
+	f.Lock()
+	defer f.Unlock()
+	unlock, err := lockFile(f.path())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f.secrets = nil
+	if err = f.load(); err != nil {
+		return err
+	}
+	if err = modify(f.secrets); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *fileStore) path() string {
+	return filepath.Join(filepath.Dir(GetConfigFilePath()), secretFileName)
+}
+
+// load reads and decrypts the secret file, once per process.
+func (f *fileStore) load() error {
+	if f.secrets != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(f.path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			f.secrets = map[string]string{}
+			return nil
+		}
+		return err
+	}
+	var ef encryptedFile
+	if err = json.Unmarshal(data, &ef); err != nil {
+		return fmt.Errorf("unknown format for secret file %s: %s", f.path(), err.Error())
+	}
+	if f.passphrase == "" {
+		if f.passphrase, err = readPassphrase("Passphrase of the secret file"); err != nil {
+			return err
+		}
+	}
+	plain, err := decryptData(f.passphrase, &ef)
+	if err != nil {
+		return fmt.Errorf("could not decrypt %s, please check the passphrase", f.path())
+	}
+	secrets := map[string]string{}
+	if err = json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("could not read secrets: %s", err.Error())
+	}
+	f.secrets = secrets
+	return nil
+}
+
+// save encrypts the secrets and writes them to the secret file.
+func (f *fileStore) save() error {
+	plain, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+	if f.passphrase == "" {
+		if f.passphrase, err = readPassphrase("Passphrase of the secret file"); err != nil {
+			return err
+		}
+	}
+	ef, err := encryptData(f.passphrase, plain)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(ef)
+	if err != nil {
+		return err
+	}
+	return writeFilePrivate(f.path(), data)
+}
+
+// readPassphrase returns the passphrase that is defined in the environment, or prompts the user for it.
+func readPassphrase(label string) (string, error) {
+	if pass := os.Getenv(SecretKeyEnv); pass != "" {
+		return pass, nil
+	}
+	p := promptui.Prompt{Label: label, Mask: '*'}
+	pass, err := p.Run()
+	if err != nil || pass == "" {
+		return "", fmt.Errorf("a passphrase is required to access encrypted secrets, you can also set %s", SecretKeyEnv)
+	}
+	return pass, nil
+}
+
+// encryptData encrypts the passed data with a key that is derived from the passphrase, using a new salt and a new nonce.
+func encryptData(passphrase string, plain []byte) (*encryptedFile, error) {
+	ef := &encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(ef.Salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newCipher(passphrase, ef.Salt)
+	if err != nil {
+		return nil, err
+	}
+	ef.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(ef.Nonce); err != nil {
+		return nil, err
+	}
+	ef.Data = gcm.Seal(nil, ef.Nonce, plain, nil)
+	return ef, nil
+}
+
+// decryptData decrypts data that has been encrypted by encryptData.
+func decryptData(passphrase string, ef *encryptedFile) ([]byte, error) {
+	gcm, err := newCipher(passphrase, ef.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, ef.Nonce, ef.Data, nil)
+}
+
+func newCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,34 @@
+package rest
+
+import "testing"
+
+func TestGetSecretStoreCommand(t *testing.T) {
+	previous := SecretCommand
+	defer func() { SecretCommand = previous }()
+
+	tests := []struct {
+		global  string
+		profile string
+		want    string
+		wantErr bool
+	}{
+		{global: "pass-helper", profile: "vault-helper", want: "vault-helper"},
+		{global: "pass-helper", profile: "", want: "pass-helper"},
+		{global: "", profile: "vault-helper", want: "vault-helper"},
+		{global: "", profile: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		SecretCommand = tt.global
+		store, err := GetSecretStore(SecretBackendCommand, tt.profile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetSecretStore(%q) with %q error = %v, wantErr %v", tt.profile, tt.global, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got := store.(*commandStore).command; got != tt.want {
+			t.Errorf("GetSecretStore(%q) with %q uses command %q, want %q", tt.profile, tt.global, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/meta_service"
+	"github.com/pydio/cells-sdk-go/v3/client/user_meta_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// MetaTypeTags is the type of the user meta namespaces that store a comma-separated list of tags.
+const MetaTypeTags = "tags"
+
+// MetaNamespaceType retrieves the type of the namespace (e.g: "string", "tags", "stars_rate"...) from its JSON definition.
+func MetaNamespaceType(ns *models.IdmUserMetaNamespace) string {
+	var def struct {
+		Type string `json:"type"`
+	}
+	if ns.JSONDefinition == "" {
+		return ""
+	}
+	if err := json.Unmarshal([]byte(ns.JSONDefinition), &def); err != nil {
+		return ""
+	}
+	return def.Type
+}
+
+// ListMetaNamespaces retrieves the user meta namespaces that are defined on the server side.
+func ListMetaNamespaces() ([]*models.IdmUserMetaNamespace, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &user_meta_service.ListUserMetaNamespaceParams{Context: ctx}
+	res, err := client.UserMetaService.ListUserMetaNamespace(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list meta namespaces: %s", err.Error())
+	}
+	return res.Payload.Namespaces, nil
+}
+
+// GetMetaNamespace retrieves a single namespace definition by its technical name.
+func GetMetaNamespace(namespace string) (*models.IdmUserMetaNamespace, error) {
+	nss, err := ListMetaNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range nss {
+		if ns.Namespace == namespace {
+			return ns, nil
+		}
+	}
+	return nil, fmt.Errorf("no meta namespace found with name %s", namespace)
+}
+
+// GetNodeWithMeta retrieves the node at the passed path with all metadata provided by the server in its MetaStore.
+func GetNodeWithMeta(nodePath string) (*models.TreeNode, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &meta_service.GetBulkMetaParams{
+		Body: &models.RestGetBulkMetaRequest{
+			NodePaths:        []string{nodePath},
+			AllMetaProviders: true,
+		},
+		Context: ctx,
+	}
+	res, err := client.MetaService.GetBulkMeta(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Payload.Nodes) == 0 {
+		return nil, fmt.Errorf("no node found at %s", nodePath)
+	}
+	return res.Payload.Nodes[0], nil
+}
+
+// SetNodeMeta stores the passed value for this namespace on the node. The value must be valid JSON.
+func SetNodeMeta(nodeUUID, namespace, jsonValue string) error {
+	return updateNodeMeta(models.UpdateUserMetaRequestUserMetaOpPUT, nodeUUID, namespace, jsonValue)
+}
+
+// DeleteNodeMeta removes the value of the given namespace from the node.
+func DeleteNodeMeta(nodeUUID, namespace string) error {
+	return updateNodeMeta(models.UpdateUserMetaRequestUserMetaOpDELETE, nodeUUID, namespace, "")
+}
+
+func updateNodeMeta(op models.UpdateUserMetaRequestUserMetaOp, nodeUUID, namespace, jsonValue string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+
+	// Same policies as the one that are set by the web UI
+	var policies []*models.ServiceResourcePolicy
+	for _, action := range []models.ServiceResourcePolicyAction{
+		models.ServiceResourcePolicyActionOWNER,
+		models.ServiceResourcePolicyActionREAD,
+		models.ServiceResourcePolicyActionWRITE,
+	} {
+		policies = append(policies, &models.ServiceResourcePolicy{
+			Action:  models.NewServiceResourcePolicyAction(action),
+			Effect:  models.NewServiceResourcePolicyPolicyEffect(models.ServiceResourcePolicyPolicyEffectAllow),
+			Subject: "*",
+		})
+	}
+
+	params := &user_meta_service.UpdateUserMetaParams{
+		Body: &models.IdmUpdateUserMetaRequest{
+			MetaDatas: []*models.IdmUserMeta{{
+				NodeUUID:  nodeUUID,
+				Namespace: namespace,
+				JSONValue: jsonValue,
+				Policies:  policies,
+			}},
+			Operation: models.NewUpdateUserMetaRequestUserMetaOp(op),
+		},
+		Context: ctx,
+	}
+	if _, err = client.UserMetaService.UpdateUserMeta(params); err != nil {
+		return fmt.Errorf("could not update meta %s: %s", namespace, err.Error())
+	}
+	return nil
+}
+
+// ListMetaTags retrieves all the tags that have already been used for this namespace.
+func ListMetaTags(namespace string) ([]string, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &user_meta_service.ListUserMetaTagsParams{Namespace: namespace, Context: ctx}
+	res, err := client.UserMetaService.ListUserMetaTags(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags for %s: %s", namespace, err.Error())
+	}
+	return res.Payload.Tags, nil
+}
+
+// RegisterMetaTag adds a tag to the list of known values for this namespace.
+func RegisterMetaTag(namespace, tag string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &user_meta_service.PutUserMetaTagParams{
+		Namespace: namespace,
+		Body:      &models.RestPutUserMetaTagRequest{Namespace: namespace, Tag: tag},
+		Context:   ctx,
+	}
+	if _, err = client.UserMetaService.PutUserMetaTag(params); err != nil {
+		return fmt.Errorf("could not register tag %s for %s: %s", tag, namespace, err.Error())
+	}
+	return nil
+}
+
+// DecodeTags parses the JSON value of a tags namespace in a list of tags.
+func DecodeTags(jsonValue string) []string {
+	var raw string
+	if err := json.Unmarshal([]byte(jsonValue), &raw); err != nil {
+		raw = strings.Trim(jsonValue, "\"")
+	}
+	var tags []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// EncodeTags builds the JSON value that is stored for a tags namespace.
+func EncodeTags(tags []string) string {
+	data, _ := json.Marshal(strings.Join(tags, ","))
+	return string(data)
+}
+
+// ToJSONMetaValue returns the passed value as is if it is already valid JSON
+// and encodes it as a JSON string otherwise.
+func ToJSONMetaValue(value string) string {
+	if json.Valid([]byte(value)) {
+		return value
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -1,7 +1,9 @@
 package rest
 
 import (
-	"crypto/tls"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,22 +12,68 @@ import (
 	"strings"
 	"time"
 
-	cells_sdk "github.com/pydio/cells-sdk-go/v3"
-
 	"github.com/pydio/cells-client/v2/common"
 )
 
+// DefaultRefreshMargin is the default value of RefreshMargin.
+const DefaultRefreshMargin = 2 * time.Minute
+
+// RefreshMargin is the time before their expiration at which OAuth2 tokens are refreshed,
+// so that they do not expire while a request is in flight.
+var RefreshMargin = DefaultRefreshMargin
+
 type tokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	ExpiresIn    int    `json:"expires_in"`
 	IdToken      string `json:"id_token"`
 	RefreshToken string `json:"refresh_token"`
 	// if the server returns an error, we will have this field so that we can check.
-	StatusCode int `json:"status_code"`
+	StatusCode       int    `json:"status_code"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
 }
 
-// OAuthPrepareUrl makes a URL that can be opened in browser or copy/pasted by user
-func OAuthPrepareUrl(serverUrl, state string, browser bool) (redirectUrl string, callbackUrl string, e error) {
+// oidcDiscovery holds the endpoints that are published by the OpenID provider of the server.
+type oidcDiscovery struct {
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+}
+
+// DeviceAuthorization is the response of the server when starting a device authorization flow (RFC 8628).
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+
+	tokenEndpoint string
+}
+
+const (
+	oauthScopes     = "openid email profile pydio offline"
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// NewPKCEVerifier generates a random code verifier for the PKCE extension of the authorization code flow (RFC 7636).
+func NewPKCEVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate PKCE verifier: %s", err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OAuthPrepareUrl makes a URL that can be opened in browser or copy/pasted by user.
+// If codeVerifier is not empty, the corresponding PKCE challenge is added to the request.
+func OAuthPrepareUrl(serverUrl, state, codeVerifier string, browser bool) (redirectUrl string, callbackUrl string, e error) {
 
 	authU, _ := url.Parse(serverUrl)
 	authU.Path = "/oidc/oauth2/auth"
@@ -35,8 +83,12 @@ func OAuthPrepareUrl(serverUrl, state string, browser bool) (redirectUrl string,
 	// if clientSecret != "" {
 	// 	values.Add("client_secret", clientSecret)
 	// }
-	values.Add("scope", "openid email profile pydio offline")
+	values.Add("scope", oauthScopes)
 	values.Add("state", state)
+	if codeVerifier != "" {
+		values.Add("code_challenge", pkceChallenge(codeVerifier))
+		values.Add("code_challenge_method", "S256")
+	}
 	if browser {
 		callbackUrl = "http://localhost:3000/servers/callback"
 	} else {
@@ -50,8 +102,13 @@ func OAuthPrepareUrl(serverUrl, state string, browser bool) (redirectUrl string,
 	return
 }
 
-// OAuthExchangeCode gets an OAuth code and retrieves an AccessToken/RefreshToken pair. It updates the passed Conf
-func OAuthExchangeCode(c *cells_sdk.SdkConfig, code, callbackUrl string) error {
+// OAuthExchangeCode gets an OAuth code and retrieves an AccessToken/RefreshToken pair. It updates the passed Conf.
+// The codeVerifier must be the one that has been used to prepare the URL, if any.
+func OAuthExchangeCode(c *CecConfig, code, callbackUrl, codeVerifier string) error {
+	client, err := oauthHTTPClient(c)
+	if err != nil {
+		return err
+	}
 	tokenU, _ := url.Parse(c.Url)
 	tokenU.Path = "/oidc/oauth2/token"
 	values := url.Values{}
@@ -59,20 +116,24 @@ func OAuthExchangeCode(c *cells_sdk.SdkConfig, code, callbackUrl string) error {
 	values.Add("code", code)
 	values.Add("redirect_uri", callbackUrl)
 	values.Add("client_id", common.AppName)
-	if c.SkipVerify {
-		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	if codeVerifier != "" {
+		values.Add("code_verifier", codeVerifier)
 	}
-	resp, err := http.Post(tokenU.String(), "application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+	resp, err := client.Post(tokenU.String(), "application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	b, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not perfom authentication flow: received status code %d - %s", resp.StatusCode, string(b))
+	}
 	var r tokenResponse
 	if err := json.Unmarshal(b, &r); err != nil {
 		return err
 	}
 
-	if r.StatusCode > 299 {
+	if r.StatusCode > 299 || r.AccessToken == "" {
 		return fmt.Errorf("could not perfom authentication flow: response body %s", string(b))
 	}
 
@@ -84,20 +145,17 @@ func OAuthExchangeCode(c *cells_sdk.SdkConfig, code, callbackUrl string) error {
 }
 
 // RefreshIfRequired refreshes the token inside the given conf if required.
+// It does not store the new tokens, use RefreshAndStoreIfRequired for this.
 func RefreshIfRequired(conf *CecConfig) (bool, error) {
-	// No token to refresh
-	if conf.IdToken == "" || conf.RefreshToken == "" || conf.TokenExpiresAt == 0 {
-		return false, nil
-	}
-	// Not yet expired, ignore
-	if time.Unix(int64(conf.TokenExpiresAt), 0).After(time.Now()) {
+	if !needsRefresh(conf) {
 		return false, nil
 	}
 	data := url.Values{}
 	data.Add("grant_type", "refresh_token")
 	data.Add("client_id", common.AppName)
-	data.Add("refresh_token", conf.RefreshToken)
-	data.Add("scope", "openid email profile pydio offline")
+	_, refreshToken, _ := conf.tokens()
+	data.Add("refresh_token", refreshToken)
+	data.Add("scope", oauthScopes)
 
 	httpReq, err := http.NewRequest("POST", conf.Url+"/oidc/oauth2/token", strings.NewReader(data.Encode()))
 	if err != nil {
@@ -106,25 +164,198 @@ func RefreshIfRequired(conf *CecConfig) (bool, error) {
 	httpReq.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	httpReq.Header.Add("Cache-Control", "no-cache")
 
-	client := http.DefaultClient
-	if conf.SkipVerify {
-		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	client, err := oauthHTTPClient(conf)
+	if err != nil {
+		return true, err
 	}
 	res, err := client.Do(httpReq)
 	if err != nil {
 		return true, err
-	} else if res.StatusCode != 200 {
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
 		bb, _ := ioutil.ReadAll(res.Body)
 		return true, fmt.Errorf("received status code %d - %s", res.StatusCode, string(bb))
 	}
-	defer res.Body.Close()
 	var respMap tokenResponse
 	err = json.NewDecoder(res.Body).Decode(&respMap)
 	if err != nil {
 		return true, fmt.Errorf("could not unmarshall response with status %d: %s\nerror cause: %s", res.StatusCode, res.Status, err.Error())
 	}
-	conf.IdToken = respMap.AccessToken
-	conf.RefreshToken = respMap.RefreshToken
-	conf.TokenExpiresAt = int(time.Now().Unix()) + respMap.ExpiresIn
+	conf.setTokens(respMap.AccessToken, respMap.RefreshToken, int(time.Now().Unix())+respMap.ExpiresIn)
 	return true, nil
 }
+
+// needsRefresh tells if the conf holds OAuth2 tokens that expire within the RefreshMargin.
+func needsRefresh(conf *CecConfig) bool {
+	// No token to refresh
+	idToken, refreshToken, expiresAt := conf.tokens()
+	if idToken == "" || refreshToken == "" || expiresAt == 0 {
+		return false
+	}
+	// Not yet expired, ignore
+	return !time.Unix(int64(expiresAt), 0).After(time.Now().Add(RefreshMargin))
+}
+
+// OAuthStartDeviceFlow starts a device authorization flow: the user must then open the verification URL
+// on any device with a browser, and enter the user code, while OAuthPollDeviceToken waits for the tokens.
+func OAuthStartDeviceFlow(c *CecConfig) (*DeviceAuthorization, error) {
+	client, err := oauthHTTPClient(c)
+	if err != nil {
+		return nil, err
+	}
+	discovery, err := oidcDiscover(client, c.Url)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("the server does not support the device authorization flow")
+	}
+
+	values := url.Values{}
+	values.Add("client_id", common.AppName)
+	values.Add("scope", oauthScopes)
+	resp, err := client.PostForm(discovery.DeviceAuthorizationEndpoint, values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not start device authorization flow: received status code %d - %s", resp.StatusCode, string(b))
+	}
+	da := &DeviceAuthorization{}
+	if err = json.Unmarshal(b, da); err != nil {
+		return nil, fmt.Errorf("could not unmarshall device authorization response: %s", err.Error())
+	}
+	if da.DeviceCode == "" || da.UserCode == "" {
+		return nil, fmt.Errorf("invalid device authorization response: %s", string(b))
+	}
+	if da.Interval <= 0 {
+		da.Interval = 5
+	}
+	da.tokenEndpoint = discovery.TokenEndpoint
+	if da.tokenEndpoint == "" {
+		da.tokenEndpoint = c.Url + "/oidc/oauth2/token"
+	}
+	return da, nil
+}
+
+// OAuthPollDeviceToken polls the token endpoint until the user has approved (or denied) the device authorization,
+// or until it expires. On success, the passed Conf is updated with the retrieved tokens.
+func OAuthPollDeviceToken(c *CecConfig, da *DeviceAuthorization) error {
+	client, err := oauthHTTPClient(c)
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(da.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(da.ExpiresIn) * time.Second)
+
+	values := url.Values{}
+	values.Add("grant_type", deviceGrantType)
+	values.Add("device_code", da.DeviceCode)
+	values.Add("client_id", common.AppName)
+
+	for {
+		if da.ExpiresIn > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("the device code has expired, please start again")
+		}
+		time.Sleep(interval)
+
+		resp, err := client.PostForm(da.tokenEndpoint, values)
+		if err != nil {
+			return err
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		var r tokenResponse
+		if err = json.Unmarshal(b, &r); err != nil {
+			return fmt.Errorf("could not unmarshall response with status %d: %s", resp.StatusCode, string(b))
+		}
+
+		switch r.Error {
+		case "":
+			if resp.StatusCode != http.StatusOK || r.AccessToken == "" {
+				return fmt.Errorf("received status code %d - %s", resp.StatusCode, string(b))
+			}
+			c.IdToken = r.AccessToken
+			c.RefreshToken = r.RefreshToken
+			c.TokenExpiresAt = int(time.Now().Unix()) + r.ExpiresIn
+			return nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return fmt.Errorf("the authorization request has been denied")
+		case "expired_token":
+			return fmt.Errorf("the device code has expired, please start again")
+		default:
+			return fmt.Errorf("could not perform device authorization flow: %s %s", r.Error, r.ErrorDescription)
+		}
+	}
+}
+
+// OAuthRevokeTokens revokes the refresh token and the access token of the passed OAuth2 config on the server (RFC 7009),
+// so that they cannot be used anymore, even if they have been copied. Nothing is done for other authentication types.
+func OAuthRevokeTokens(c *CecConfig) error {
+	if c.AuthType != common.OAuthType {
+		return nil
+	}
+	client, err := oauthHTTPClient(c)
+	if err != nil {
+		return err
+	}
+	endpoint := c.Url + "/oidc/oauth2/revoke"
+	if d, err := oidcDiscover(client, c.Url); err == nil && d.RevocationEndpoint != "" {
+		endpoint = d.RevocationEndpoint
+	}
+
+	// Revoke the refresh token first: it is the long-lived one
+	for _, t := range []struct{ hint, token string }{
+		{"refresh_token", c.RefreshToken},
+		{"access_token", c.IdToken},
+	} {
+		if t.token == "" {
+			continue
+		}
+		values := url.Values{}
+		values.Add("token", t.token)
+		values.Add("token_type_hint", t.hint)
+		values.Add("client_id", common.AppName)
+		resp, err := client.PostForm(endpoint, values)
+		if err != nil {
+			return fmt.Errorf("could not revoke %s: %s", t.hint, err.Error())
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not revoke %s: received status code %d - %s", t.hint, resp.StatusCode, string(b))
+		}
+	}
+	return nil
+}
+
+func oidcDiscover(client *http.Client, serverUrl string) (*oidcDiscovery, error) {
+	resp, err := client.Get(serverUrl + "/oidc/.well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve OpenID configuration: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not retrieve OpenID configuration: received status code %d", resp.StatusCode)
+	}
+	d := &oidcDiscovery{}
+	if err = json.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, fmt.Errorf("could not unmarshall OpenID configuration: %s", err.Error())
+	}
+	return d, nil
+}
+
+func oauthHTTPClient(c *CecConfig) (*http.Client, error) {
+	client, err := NewHTTPClient(c)
+	if err != nil {
+		return nil, err
+	}
+	client.Timeout = 30 * time.Second
+	return client, nil
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/pydio/cells-client/v2/common"
+)
+
+// MaxPresignDuration is the longest validity that is accepted by the S3 signature v4.
+const MaxPresignDuration = 7 * 24 * time.Hour
+
+// PresignGet creates a signed URL that can be used to download the object at the passed path without further authentication.
+func PresignGet(nodePath string, expires time.Duration) (string, error) {
+	return presign(nodePath, expires, func(c *s3.S3, bucket, key string) *request.Request {
+		req, _ := c.GetObjectRequest(&s3.GetObjectInput{Bucket: &bucket, Key: &key})
+		return req
+	})
+}
+
+// PresignPut creates a signed URL that can be used to upload an object at the passed path with a simple HTTP PUT request.
+func PresignPut(nodePath string, expires time.Duration) (string, error) {
+	return presign(nodePath, expires, func(c *s3.S3, bucket, key string) *request.Request {
+		req, _ := c.PutObjectRequest(&s3.PutObjectInput{Bucket: &bucket, Key: &key})
+		return req
+	})
+}
+
+// CredentialsExpiry returns the time at which the token that signs the URLs expires, if it is known.
+// Signed URLs cannot be used anymore once this token has expired, whatever their own validity.
+func CredentialsExpiry() (time.Time, bool) {
+	if _, _, expiresAt := DefaultConfig.tokens(); DefaultConfig.AuthType == common.OAuthType && expiresAt > 0 {
+		return time.Unix(int64(expiresAt), 0), true
+	}
+	return time.Time{}, false
+}
+
+func presign(nodePath string, expires time.Duration, build func(c *s3.S3, bucket, key string) *request.Request) (string, error) {
+	if expires <= 0 || expires > MaxPresignDuration {
+		return "", fmt.Errorf("validity must be positive and at most %s", MaxPresignDuration)
+	}
+	s3Client, bucketName, err := GetS3Client()
+	if err != nil {
+		return "", err
+	}
+	req := build(s3Client, bucketName, strings.Trim(nodePath, "/"))
+	u, err := req.Presign(expires)
+	if err != nil {
+		return "", fmt.Errorf("could not sign URL for %s: %s", nodePath, err.Error())
+	}
+	return u, nil
+}
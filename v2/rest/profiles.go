@@ -0,0 +1,240 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pydio/cells-client/v2/common"
+)
+
+// Keys of the profile properties that can be modified with SetProperty.
+const (
+	PropertyLabel      = "label"
+	PropertySkipVerify = "skipVerify"
+	PropertyCAFile     = "caFile"
+	PropertyClientCert = "clientCertFile"
+	PropertyClientKey  = "clientKeyFile"
+	PropertyProxyURL   = "proxyUrl"
+	// PropertyHeaderPrefix prefixes the name of a custom header that is sent with each request, e.g: header.X-Tenant
+	PropertyHeaderPrefix = "header."
+)
+
+const profileExportVersion = 1
+
+// profileExport is the format of the files that are produced by ExportProfiles.
+type profileExport struct {
+	Version  int                   `json:"version"`
+	Profiles map[string]*CecConfig `json:"profiles"`
+	// Secrets holds the encrypted sensitive information of the profiles, when they have been exported.
+	Secrets *encryptedFile `json:"secrets,omitempty"`
+}
+
+// profileSecrets holds the sensitive information of a single profile.
+type profileSecrets struct {
+	IdToken        string `json:"idToken,omitempty"`
+	RefreshToken   string `json:"refreshToken,omitempty"`
+	TokenExpiresAt int    `json:"tokenExpiresAt,omitempty"`
+	Password       string `json:"password,omitempty"`
+	ClientSecret   string `json:"clientSecret,omitempty"`
+}
+
+// ExportProfiles serializes the profiles with the passed IDs, or all profiles if none is passed.
+// Sensitive information is left out, unless withSecrets is set: it is then encrypted with a passphrase
+// that is read from the environment or prompted.
+func ExportProfiles(ids []string, withSecrets bool) ([]byte, error) {
+	list, err := GetConfigList()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		for id := range list.Configs {
+			ids = append(ids, id)
+		}
+	}
+
+	export := profileExport{Version: profileExportVersion, Profiles: map[string]*CecConfig{}}
+	secrets := map[string]profileSecrets{}
+	for _, id := range ids {
+		stored, ok := list.Configs[id]
+		if !ok {
+			return nil, fmt.Errorf("config not found, ID is not valid [%s]", id)
+		}
+		// Work on a copy, the secrets are removed before export
+		c := *stored
+		if withSecrets && !c.SkipKeyring {
+			if err = ConfigFromKeyring(&c); err != nil {
+				return nil, fmt.Errorf("could not retrieve the secrets of %s: %s", id, err.Error())
+			}
+		}
+		if withSecrets {
+			secrets[id] = profileSecrets{
+				IdToken:        c.IdToken,
+				RefreshToken:   c.RefreshToken,
+				TokenExpiresAt: c.TokenExpiresAt,
+				Password:       c.Password,
+				ClientSecret:   c.ClientSecret,
+			}
+		}
+		clearSecrets(&c)
+		// Secrets of the new machine are stored in its own default backend
+		c.SkipKeyring = false
+		c.SecretBackend = ""
+		c.SecretCommand = ""
+		export.Profiles[id] = &c
+	}
+
+	if withSecrets {
+		passphrase, err := readPassphrase("Passphrase to encrypt the exported secrets")
+		if err != nil {
+			return nil, err
+		}
+		plain, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, err
+		}
+		if export.Secrets, err = encryptData(passphrase, plain); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(export, "", "\t")
+}
+
+// ImportProfiles adds the profiles of a file that has been produced by ExportProfiles to the config file.
+// Existing profiles with the same ID are only replaced if overwrite is set, the IDs of the skipped profiles are returned.
+// Imported secrets are stored in the secret backend of the current machine.
+func ImportProfiles(data []byte, overwrite bool) (imported []string, skipped []string, err error) {
+	var export profileExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		return nil, nil, fmt.Errorf("unknown format for profile export: %s", err.Error())
+	}
+	if export.Version != profileExportVersion || len(export.Profiles) == 0 {
+		return nil, nil, fmt.Errorf("no profile found, is this a file that has been produced by 'config export'?")
+	}
+
+	secrets := map[string]profileSecrets{}
+	if export.Secrets != nil {
+		passphrase, e := readPassphrase("Passphrase of the exported secrets")
+		if e != nil {
+			return nil, nil, e
+		}
+		plain, e := decryptData(passphrase, export.Secrets)
+		if e != nil {
+			return nil, nil, fmt.Errorf("could not decrypt the exported secrets, please check the passphrase")
+		}
+		if e = json.Unmarshal(plain, &secrets); e != nil {
+			return nil, nil, fmt.Errorf("could not read secrets: %s", e.Error())
+		}
+	}
+
+	list, err := GetConfigList()
+	if err != nil {
+		return nil, nil, err
+	}
+	toImport := map[string]*CecConfig{}
+	for id, c := range export.Profiles {
+		if c == nil || c.Url == "" {
+			return nil, nil, fmt.Errorf("profile %s has no URL", id)
+		}
+		if _, ok := list.Configs[id]; ok && !overwrite {
+			skipped = append(skipped, id)
+			continue
+		}
+		clearSecrets(c)
+		if s, ok := secrets[id]; ok {
+			c.IdToken = s.IdToken
+			c.RefreshToken = s.RefreshToken
+			c.TokenExpiresAt = s.TokenExpiresAt
+			c.Password = s.Password
+			c.ClientSecret = s.ClientSecret
+			if err = ConfigToKeyring(c); err != nil {
+				fmt.Printf("Could not store the secrets of %s: %s\n", id, NoKeyringMsg)
+				// Force skip keyring flag in the config file to be explicit
+				c.SkipKeyring = true
+			}
+		}
+		if c.CreatedAtVersion == "" {
+			c.CreatedAtVersion = common.Version
+		}
+		toImport[id] = c
+		imported = append(imported, id)
+	}
+
+	err = UpdateConfigList(func(list *ConfigList) error {
+		for id, c := range toImport {
+			list.Configs[id] = c
+		}
+		if list.ActiveConfigID == "" && len(imported) > 0 {
+			sort.Strings(imported)
+			list.ActiveConfigID = imported[0]
+		}
+		return nil
+	})
+	sort.Strings(imported)
+	sort.Strings(skipped)
+	return imported, skipped, err
+}
+
+// SetProperty modifies one of the properties of the profile that can be safely edited, see the Property constants.
+// Setting an empty value for a custom header removes it.
+func (c *CecConfig) SetProperty(key, value string) error {
+	switch {
+	case key == PropertyLabel:
+		if value == "" {
+			return fmt.Errorf("label cannot be empty")
+		}
+		c.Label = value
+	case strings.EqualFold(key, PropertySkipVerify):
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %s for %s, expected true or false", value, PropertySkipVerify)
+		}
+		c.SkipVerify = b
+	case key == PropertyCAFile:
+		c.CAFile = value
+	case key == PropertyClientCert:
+		c.ClientCertFile = value
+	case key == PropertyClientKey:
+		c.ClientKeyFile = value
+	case key == PropertyProxyURL:
+		c.ProxyURL = value
+	case strings.HasPrefix(key, PropertyHeaderPrefix) && len(key) > len(PropertyHeaderPrefix):
+		name := strings.TrimPrefix(key, PropertyHeaderPrefix)
+		if strings.EqualFold(name, "User-Agent") || strings.EqualFold(name, "Authorization") {
+			return fmt.Errorf("header %s is managed by the client and cannot be overridden", name)
+		}
+		if value == "" {
+			delete(c.CustomHeaders, name)
+			return nil
+		}
+		if c.CustomHeaders == nil {
+			c.CustomHeaders = map[string]string{}
+		}
+		c.CustomHeaders[name] = value
+	default:
+		return fmt.Errorf("unknown property %s, expected one of %s, %s, %s, %s, %s, %s or %s<name>", key,
+			PropertyLabel, PropertySkipVerify, PropertyCAFile, PropertyClientCert, PropertyClientKey, PropertyProxyURL, PropertyHeaderPrefix)
+	}
+	return nil
+}
+
+// clearSecrets removes the sensitive information from the passed config.
+func clearSecrets(c *CecConfig) {
+	c.IdToken = ""
+	c.RefreshToken = ""
+	c.TokenExpiresAt = 0
+	c.Password = ""
+	c.ClientSecret = ""
+}
+
+// addUserAgent adds the User-Agent of the client to the custom headers that are defined in the config.
+func addUserAgent(c *CecConfig) {
+	headers := map[string]string{}
+	for k, v := range c.CustomHeaders {
+		headers[k] = v
+	}
+	headers["User-Agent"] = common.AppName + "/" + common.Version
+	c.CustomHeaders = headers
+}
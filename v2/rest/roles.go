@@ -0,0 +1,248 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/pydio/cells-sdk-go/v3/client/acl_service"
+	"github.com/pydio/cells-sdk-go/v3/client/role_service"
+	"github.com/pydio/cells-sdk-go/v3/client/user_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// Names of the ACL actions that define the rights on a workspace.
+const (
+	AclRead  = "read"
+	AclWrite = "write"
+	AclDeny  = "deny"
+)
+
+// ListRoles retrieves all roles, including the technical roles of users and groups if technical is true.
+func ListRoles(technical bool) ([]*models.IdmRole, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	var roles []*models.IdmRole
+	var offset int
+	for {
+		params := &role_service.SearchRolesParams{
+			Body: &models.RestSearchRoleRequest{
+				Limit:  strconv.Itoa(listPageSize),
+				Offset: strconv.Itoa(offset),
+			},
+			Context: ctx,
+		}
+		res, err := client.RoleService.SearchRoles(params)
+		if err != nil {
+			return nil, fmt.Errorf("could not list roles: %s", err.Error())
+		}
+		for _, r := range res.Payload.Roles {
+			if technical || (!r.UserRole && !r.GroupRole) {
+				roles = append(roles, r)
+			}
+		}
+		nb := len(res.Payload.Roles)
+		offset += nb
+		if nb < listPageSize {
+			break
+		}
+	}
+	return roles, nil
+}
+
+// FindRole retrieves a role given its UUID or its label.
+func FindRole(id string) (*models.IdmRole, error) {
+	roles, err := ListRoles(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if r.UUID == id {
+			return r, nil
+		}
+	}
+	for _, r := range roles {
+		if r.Label == id && !r.UserRole && !r.GroupRole {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no role found with ID or label %s", id)
+}
+
+// CreateRole creates a new role. If roleID is empty, a random UUID is used.
+// autoApplies lists the profiles the role is automatically applied to, e.g. standard.
+func CreateRole(label, roleID string, autoApplies []string) (*models.IdmRole, error) {
+	if roleID == "" {
+		roleID = uuid.New().String()
+	}
+	if _, err := FindRole(roleID); err == nil {
+		return nil, fmt.Errorf("role %s already exists", roleID)
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &role_service.SetRoleParams{
+		UUID:    roleID,
+		Body:    &models.IdmRole{UUID: roleID, Label: label, AutoApplies: autoApplies},
+		Context: ctx,
+	}
+	res, err := client.RoleService.SetRole(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not create role %s: %s", label, err.Error())
+	}
+	return res.Payload, nil
+}
+
+// DeleteRole removes a role. Users and groups that have this role lose the corresponding rights.
+func DeleteRole(roleID string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &role_service.DeleteRoleParams{UUID: roleID, Context: ctx}
+	if _, err = client.RoleService.DeleteRole(params); err != nil {
+		return fmt.Errorf("could not delete role %s: %s", roleID, err.Error())
+	}
+	return nil
+}
+
+// AssignRoleToUser adds the role to the roles of the user, or removes it if assign is false.
+func AssignRoleToUser(role *models.IdmRole, login string, assign bool) error {
+	u, err := FindUser(login)
+	if err != nil {
+		return err
+	}
+	if u.Roles, err = updateRoleList(u.Roles, role, assign); err != nil {
+		return err
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &user_service.PutRolesParams{Login: u.Login, Body: u, Context: ctx}
+	if _, err = client.UserService.PutRoles(params); err != nil {
+		return fmt.Errorf("could not update roles of %s: %s", login, err.Error())
+	}
+	return nil
+}
+
+// AssignRoleToGroup adds the role to the roles of the group, or removes it if assign is false.
+// The role is then inherited by all users of the group. Unlike users, groups have no login that the
+// PutRoles endpoint could address: their roles are saved with the group itself, that keeps its own group role.
+func AssignRoleToGroup(role *models.IdmRole, groupPath string, assign bool) error {
+	g, err := FindGroup(groupPath)
+	if err != nil {
+		return err
+	}
+	if g.Roles, err = updateRoleList(g.Roles, role, assign); err != nil {
+		return err
+	}
+	_, err = saveGroup(g, GroupFullPath(g))
+	return err
+}
+
+// SetWorkspaceRights replaces the rights that the role grants on the workspace.
+// Rights are a combination of r and w, "deny" to explicitly deny access, or empty to remove all rights.
+func SetWorkspaceRights(roleID, workspaceID, rights string) error {
+	ws, err := FindWorkspace(workspaceID)
+	if err != nil {
+		return err
+	}
+	var actions []string
+	switch rights {
+	case "", "none":
+	case AclDeny:
+		actions = []string{AclDeny}
+	default:
+		if strings.Trim(rights, "rw") != "" {
+			return fmt.Errorf("invalid rights %s, expected r, w, rw, deny or none", rights)
+		}
+		if strings.Contains(rights, "r") {
+			actions = append(actions, AclRead)
+		}
+		if strings.Contains(rights, "w") {
+			actions = append(actions, AclWrite)
+		}
+	}
+
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+
+	// First remove the existing rights of the role on this workspace
+	search := &acl_service.SearchAclsParams{
+		Body: &models.RestSearchACLRequest{
+			Queries: []*models.IdmACLSingleQuery{{
+				RoleIDs:      []string{roleID},
+				WorkspaceIDs: []string{ws.UUID},
+				Actions: []*models.IdmACLAction{
+					{Name: AclRead}, {Name: AclWrite}, {Name: AclDeny},
+				},
+			}},
+		},
+		Context: ctx,
+	}
+	existing, err := client.ACLService.SearchAcls(search)
+	if err != nil {
+		return fmt.Errorf("could not list current rights: %s", err.Error())
+	}
+	for _, acl := range existing.Payload.ACLs {
+		if _, err = client.ACLService.DeleteACL(&acl_service.DeleteACLParams{Body: acl, Context: ctx}); err != nil {
+			return fmt.Errorf("could not remove ACL %s: %s", acl.ID, err.Error())
+		}
+	}
+
+	// Then grant the new rights on all roots of the workspace
+	for _, root := range ws.RootNodes {
+		for _, a := range actions {
+			acl := &models.IdmACL{
+				Action:      &models.IdmACLAction{Name: a, Value: "1"},
+				RoleID:      roleID,
+				WorkspaceID: ws.UUID,
+				NodeID:      root.UUID,
+			}
+			if _, err = client.ACLService.PutACL(&acl_service.PutACLParams{Body: acl, Context: ctx}); err != nil {
+				return fmt.Errorf("could not grant %s on %s: %s", a, ws.Slug, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func updateRoleList(roles []*models.IdmRole, role *models.IdmRole, assign bool) ([]*models.IdmRole, error) {
+	var updated []*models.IdmRole
+	var found bool
+	for _, r := range roles {
+		if r.UUID == role.UUID {
+			found = true
+			if !assign {
+				continue
+			}
+		}
+		updated = append(updated, r)
+	}
+	if assign && found {
+		return nil, fmt.Errorf("role %s is already assigned", role.Label)
+	} else if !assign && !found {
+		return nil, fmt.Errorf("role %s is not assigned", role.Label)
+	}
+	if assign {
+		updated = append(updated, role)
+	}
+	return updated, nil
+}
+
+// RoleMembers retrieves the users and the groups that have the passed role.
+func RoleMembers(roleID string) (users []*models.IdmUser, groups []*models.IdmUser, e error) {
+	res, err := searchUsers(&models.IdmUserSingleQuery{HasRole: roleID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list members of role %s: %s", roleID, err.Error())
+	}
+	return res.Users, res.Groups, nil
+}
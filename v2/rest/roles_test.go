@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cells_sdk "github.com/pydio/cells-sdk-go/v3"
+	"github.com/pydio/cells-sdk-go/v3/models"
+
+	"github.com/pydio/cells-client/v2/common"
+)
+
+// setUpApiClient points the REST client of the package to the passed server for the duration of the test.
+func setUpApiClient(t *testing.T, serverURL string) {
+	t.Helper()
+	previousConfig, previousOnce := DefaultConfig, once
+	DefaultConfig = &CecConfig{
+		SdkConfig: cells_sdk.SdkConfig{
+			Url:            serverURL,
+			IdToken:        "access-0",
+			TokenExpiresAt: int(time.Now().Add(time.Hour).Unix()),
+		},
+		AuthType: common.OAuthType,
+	}
+	once = &sync.Once{}
+	t.Cleanup(func() {
+		DefaultConfig, once = previousConfig, previousOnce
+	})
+}
+
+func TestAssignRoleToGroup(t *testing.T) {
+	groupRole := &models.IdmRole{UUID: "group-uuid", GroupRole: true}
+	editors := &models.IdmRole{UUID: "editors", Label: "Editors"}
+	reviewers := &models.IdmRole{UUID: "reviewers", Label: "Reviewers"}
+
+	var mux sync.Mutex
+	var saved []*models.IdmUser
+	var savedLogins []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-0" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/a/user":
+			_ = json.NewEncoder(w).Encode(&models.RestUsersCollection{Groups: []*models.IdmUser{{
+				UUID:       "group-uuid",
+				IsGroup:    true,
+				GroupPath:  "/engineering/backend",
+				GroupLabel: "backend",
+				Roles:      []*models.IdmRole{groupRole, editors},
+			}}})
+		case r.Method == http.MethodPut:
+			g := &models.IdmUser{}
+			if err := json.NewDecoder(r.Body).Decode(g); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mux.Lock()
+			saved = append(saved, g)
+			savedLogins = append(savedLogins, r.URL.EscapedPath())
+			mux.Unlock()
+			_ = json.NewEncoder(w).Encode(g)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setUpApiClient(t, srv.URL)
+
+	if err := AssignRoleToGroup(reviewers, "/engineering/backend", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := AssignRoleToGroup(editors, "engineering/backend", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := AssignRoleToGroup(editors, "/engineering/backend", true); err == nil {
+		t.Error("expected an error when the role is already assigned")
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(saved) != 2 {
+		t.Fatalf("group has been saved %d times, want 2", len(saved))
+	}
+	want := [][]string{
+		{"group-uuid", "editors", "reviewers"},
+		{"group-uuid"},
+	}
+	for i, g := range saved {
+		if savedLogins[i] != "/a/user/engineering%2Fbackend" {
+			t.Errorf("group has been saved at %s, want /a/user/engineering%%2Fbackend", savedLogins[i])
+		}
+		if !g.IsGroup || g.UUID != "group-uuid" || g.GroupPath != "/engineering/backend" || g.GroupLabel != "backend" {
+			t.Errorf("saved group has lost its identity: %+v", g)
+		}
+		var ids []string
+		for _, r := range g.Roles {
+			ids = append(ids, r.UUID)
+		}
+		if len(ids) != len(want[i]) {
+			t.Errorf("saved roles = %v, want %v", ids, want[i])
+			continue
+		}
+		for j := range ids {
+			if ids[j] != want[i][j] {
+				t.Errorf("saved roles = %v, want %v", ids, want[i])
+				break
+			}
+		}
+	}
+}
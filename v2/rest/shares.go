@@ -2,40 +2,176 @@ package rest
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/pydio/cells-sdk-go/v3/client/share_service"
 	"github.com/pydio/cells-sdk-go/v3/models"
 )
 
-func CreateSimpleFolderLink(targetNodeUuid, label string) (*models.RestShareLink, error) {
+const (
+	// Templates used by the web UI to display the public links.
+	linkTemplateFolder = "pydio_shared_folder"
+	linkTemplateFile   = "pydio_unique_strip"
+)
 
-	ctx, client, e := GetApiClient()
-	if e != nil {
-		return nil, e
-	}
+// ShareLinkOptions gathers the optional settings of a public link.
+// Zero values leave the corresponding setting untouched when updating an existing link.
+type ShareLinkOptions struct {
+	Label        string
+	Description  string
+	Password     string
+	CustomHash   string
+	ExpireAt     time.Time
+	MaxDownloads int64
+	Upload       bool
+	NoDownload   bool
+	// UpdatePermissions forces the permissions of an existing link to be recomputed from Upload and NoDownload.
+	UpdatePermissions bool
+	// ClearExpiry and ClearMaxDownloads remove the expiration date and the download limit of an existing link.
+	ClearExpiry       bool
+	ClearMaxDownloads bool
+}
 
-	perm := []*models.RestShareLinkAccessType{
-		models.NewRestShareLinkAccessType(models.RestShareLinkAccessTypeDownload),
-		models.NewRestShareLinkAccessType(models.RestShareLinkAccessTypePreview),
+// CreateShareLink creates a public link on the passed node with the given options.
+func CreateShareLink(node *models.TreeNode, opts *ShareLinkOptions) (*models.RestShareLink, error) {
+	isFolder := node.Type != nil && *node.Type == models.TreeNodeTypeCOLLECTION
+	if opts.Upload && !isFolder {
+		return nil, fmt.Errorf("upload permission can only be granted on folders")
 	}
 
+	template := linkTemplateFile
+	if isFolder {
+		template = linkTemplateFolder
+	}
 	link := &models.RestShareLink{
-		Label:                   label,
-		RootNodes:               []*models.TreeNode{{UUID: targetNodeUuid}},
-		Permissions:             perm,
-		ViewTemplateName:        "pydio_shared_folder",
+		RootNodes:               []*models.TreeNode{{UUID: node.UUID}},
+		ViewTemplateName:        template,
 		PoliciesContextEditable: true,
 	}
+	applyLinkOptions(link, opts)
 
-	params := (&share_service.PutShareLinkParams{}).WithContext(ctx).WithBody(&models.RestPutShareLinkRequest{
-		ShareLink:       link,
-		PasswordEnabled: false,
-	})
+	req := &models.RestPutShareLinkRequest{ShareLink: link}
+	if opts.Password != "" {
+		req.PasswordEnabled = true
+		req.CreatePassword = opts.Password
+	}
+	if opts.CustomHash != "" {
+		req.UpdateCustomHash = opts.CustomHash
+	}
+	return putShareLink(req)
+}
 
-	resp, err := client.ShareService.PutShareLink(params)
+// UpdateShareLink modifies an existing public link. If removePassword is true, the password protection is removed.
+func UpdateShareLink(link *models.RestShareLink, opts *ShareLinkOptions, removePassword bool) (*models.RestShareLink, error) {
+	applyLinkOptions(link, opts)
+
+	req := &models.RestPutShareLinkRequest{ShareLink: link, PasswordEnabled: link.PasswordRequired}
+	if opts.Password != "" {
+		req.PasswordEnabled = true
+		if link.PasswordRequired {
+			req.UpdatePassword = opts.Password
+		} else {
+			req.CreatePassword = opts.Password
+		}
+	} else if removePassword {
+		req.PasswordEnabled = false
+	}
+	if opts.CustomHash != "" && opts.CustomHash != link.LinkHash {
+		req.UpdateCustomHash = opts.CustomHash
+	}
+	return putShareLink(req)
+}
+
+// GetShareLink retrieves a public link by its UUID.
+func GetShareLink(linkUUID string) (*models.RestShareLink, error) {
+	ctx, client, err := GetApiClient()
 	if err != nil {
-		return nil, fmt.Errorf("call to PutShareLink for %s has failed, cause: %s", label, err.Error())
+		return nil, err
 	}
+	params := &share_service.GetShareLinkParams{UUID: linkUUID, Context: ctx}
+	res, err := client.ShareService.GetShareLink(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve link %s: %s", linkUUID, err.Error())
+	}
+	return res.Payload, nil
+}
 
+// DeleteShareLink revokes a public link: it cannot be used anymore.
+func DeleteShareLink(linkUUID string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &share_service.DeleteShareLinkParams{UUID: linkUUID, Context: ctx}
+	if _, err = client.ShareService.DeleteShareLink(params); err != nil {
+		return fmt.Errorf("could not delete link %s: %s", linkUUID, err.Error())
+	}
+	return nil
+}
+
+// ListSharedResources retrieves the nodes that are shared with the passed share type (LINKS, CELLS or ANY).
+// If ownedByMe is true, only resources shared by the current user are returned.
+func ListSharedResources(shareType models.ListSharedResourcesRequestListShareType, ownedByMe bool) ([]*models.ListSharedResourcesResponseSharedResource, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &share_service.ListSharedResourcesParams{
+		Body: &models.RestListSharedResourcesRequest{
+			ShareType:      models.NewListSharedResourcesRequestListShareType(shareType),
+			OwnedBySubject: ownedByMe,
+		},
+		Context: ctx,
+	}
+	res, err := client.ShareService.ListSharedResources(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list shared resources: %s", err.Error())
+	}
+	return res.Payload.Resources, nil
+}
+
+func applyLinkOptions(link *models.RestShareLink, opts *ShareLinkOptions) {
+	if opts.Label != "" {
+		link.Label = opts.Label
+	}
+	if opts.Description != "" {
+		link.Description = opts.Description
+	}
+	if opts.ClearExpiry {
+		link.AccessEnd = "0"
+	} else if !opts.ExpireAt.IsZero() {
+		link.AccessEnd = strconv.FormatInt(opts.ExpireAt.Unix(), 10)
+	}
+	if opts.ClearMaxDownloads {
+		link.MaxDownloads = "0"
+	} else if opts.MaxDownloads > 0 {
+		link.MaxDownloads = strconv.FormatInt(opts.MaxDownloads, 10)
+	}
+
+	perm := []*models.RestShareLinkAccessType{
+		models.NewRestShareLinkAccessType(models.RestShareLinkAccessTypePreview),
+	}
+	if !opts.NoDownload {
+		perm = append(perm, models.NewRestShareLinkAccessType(models.RestShareLinkAccessTypeDownload))
+	}
+	if opts.Upload {
+		perm = append(perm, models.NewRestShareLinkAccessType(models.RestShareLinkAccessTypeUpload))
+	}
+	if len(link.Permissions) == 0 || opts.UpdatePermissions {
+		link.Permissions = perm
+	}
+}
+
+func putShareLink(req *models.RestPutShareLinkRequest) (*models.RestShareLink, error) {
+	ctx, client, e := GetApiClient()
+	if e != nil {
+		return nil, e
+	}
+	params := (&share_service.PutShareLinkParams{}).WithContext(ctx).WithBody(req)
+	resp, err := client.ShareService.PutShareLink(params)
+	if err != nil {
+		return nil, fmt.Errorf("call to PutShareLink for %s has failed, cause: %s", req.ShareLink.Label, err.Error())
+	}
 	return resp.Payload, nil
 }
@@ -0,0 +1,820 @@
+package rest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/acl_service"
+	"github.com/pydio/cells-sdk-go/v3/client/config_service"
+	"github.com/pydio/cells-sdk-go/v3/client/role_service"
+	"github.com/pydio/cells-sdk-go/v3/client/workspace_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// IdmState describes the groups, users, roles and workspaces of a server, and the rights that roles grant on workspaces.
+// It is the schema of the manifests that are used by the idm apply and idm export commands.
+type IdmState struct {
+	Groups     []*GroupState     `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Users      []*UserState      `yaml:"users,omitempty" json:"users,omitempty"`
+	Roles      []*RoleState      `yaml:"roles,omitempty" json:"roles,omitempty"`
+	Workspaces []*WorkspaceState `yaml:"workspaces,omitempty" json:"workspaces,omitempty"`
+}
+
+// GroupState describes a group, designated by its full path.
+type GroupState struct {
+	Path        string `yaml:"path" json:"path"`
+	DisplayName string `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+}
+
+// UserState describes a user. Empty settings are left untouched, and the password is only used when the user is created.
+// Roles only list the roles that are explicitly assigned to the user, if nil the roles of the user are not managed.
+type UserState struct {
+	Login       string   `yaml:"login" json:"login"`
+	Password    string   `yaml:"password,omitempty" json:"password,omitempty"`
+	DisplayName string   `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+	Email       string   `yaml:"email,omitempty" json:"email,omitempty"`
+	GroupPath   string   `yaml:"groupPath,omitempty" json:"groupPath,omitempty"`
+	Profile     string   `yaml:"profile,omitempty" json:"profile,omitempty"`
+	Locked      *bool    `yaml:"locked,omitempty" json:"locked,omitempty"`
+	Roles       []string `yaml:"roles,omitempty" json:"roles,omitempty"`
+}
+
+// RoleState describes a role. Rights map the slugs of workspaces to the rights that the role grants on them:
+// r, w, rw or deny. If nil, the rights of the role are not managed.
+type RoleState struct {
+	ID          string            `yaml:"id" json:"id"`
+	Label       string            `yaml:"label,omitempty" json:"label,omitempty"`
+	AutoApplies []string          `yaml:"autoApplies,omitempty" json:"autoApplies,omitempty"`
+	Rights      map[string]string `yaml:"rights,omitempty" json:"rights,omitempty"`
+}
+
+// WorkspaceState describes a workspace, designated by its slug. Roots are template path IDs or datasource paths.
+type WorkspaceState struct {
+	Slug          string   `yaml:"slug" json:"slug"`
+	Label         string   `yaml:"label,omitempty" json:"label,omitempty"`
+	Description   string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Roots         []string `yaml:"roots,omitempty" json:"roots,omitempty"`
+	DefaultRights *string  `yaml:"defaultRights,omitempty" json:"defaultRights,omitempty"`
+}
+
+// Actions of the changes of a plan.
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// StateChange is a single step of a plan: the creation, the update or the deletion of an object.
+type StateChange struct {
+	Action  string
+	Kind    string
+	ID      string
+	Details []string
+
+	apply func() error
+}
+
+// String describes the change with a diff-like prefix: + for a creation, ~ for an update and - for a deletion.
+func (c *StateChange) String() string {
+	prefix := "~"
+	switch c.Action {
+	case ChangeCreate:
+		prefix = "+"
+	case ChangeDelete:
+		prefix = "-"
+	}
+	s := fmt.Sprintf("%s %s %s", prefix, c.Kind, c.ID)
+	for _, d := range c.Details {
+		s += "\n    " + d
+	}
+	return s
+}
+
+// Apply performs the change on the server.
+func (c *StateChange) Apply() error {
+	return c.apply()
+}
+
+// protectedRoles are the built-in roles that are never deleted when pruning.
+var protectedRoles = []string{RootGroupRole, "ADMINS", "EXTERNAL_USERS"}
+
+// protectedWorkspaces are the default workspaces of a fresh install that are never deleted when pruning.
+var protectedWorkspaces = []string{"common-files", "personal-files"}
+
+// ExportState retrieves the current groups, users, roles and workspaces of the server.
+// Only the workspaces that are managed by administrators are exported, technical roles are left aside.
+func ExportState() (*IdmState, error) {
+	state := &IdmState{}
+
+	groups, err := ListGroups("/", true)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		state.Groups = append(state.Groups, &GroupState{Path: GroupFullPath(g), DisplayName: g.Attributes[UserAttrDisplayName]})
+	}
+
+	allRoles, err := ListRoles(true)
+	if err != nil {
+		return nil, err
+	}
+	rolesByID := make(map[string]*models.IdmRole, len(allRoles))
+	for _, r := range allRoles {
+		rolesByID[r.UUID] = r
+	}
+
+	users, err := ListGroupMembers("/", true)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		locked := IsUserLocked(u)
+		us := &UserState{
+			Login:       u.Login,
+			DisplayName: u.Attributes[UserAttrDisplayName],
+			Email:       u.Attributes[UserAttrEmail],
+			GroupPath:   path.Join("/", u.GroupPath),
+			Profile:     u.Attributes[UserAttrProfile],
+			Locked:      &locked,
+			Roles:       assignedRoles(u, rolesByID),
+		}
+		state.Users = append(state.Users, us)
+	}
+
+	workspaces, err := listAdminWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	templates, err := listTemplatePaths()
+	if err != nil {
+		return nil, err
+	}
+	slugs := make(map[string]string, len(workspaces))
+	for _, ws := range workspaces {
+		slugs[ws.UUID] = ws.Slug
+		wss := &WorkspaceState{Slug: ws.Slug, Label: ws.Label, Description: ws.Description}
+		for _, id := range ws.RootUUIDs {
+			if templates[id] {
+				wss.Roots = append(wss.Roots, id)
+			} else if n, ok := ws.RootNodes[id]; ok && n.Path != "" {
+				wss.Roots = append(wss.Roots, strings.Trim(n.Path, "/"))
+			} else {
+				wss.Roots = append(wss.Roots, id)
+			}
+		}
+		if v, ok := WorkspaceAttributes(ws)[WsAttrDefaultRights]; ok {
+			rights := fmt.Sprintf("%v", v)
+			wss.DefaultRights = &rights
+		}
+		state.Workspaces = append(state.Workspaces, wss)
+	}
+
+	rights, err := workspaceRights(slugs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range allRoles {
+		if r.UserRole || r.GroupRole || r.UUID == RootGroupRole {
+			continue
+		}
+		rs := &RoleState{ID: r.UUID, Label: r.Label, AutoApplies: r.AutoApplies, Rights: rights[r.UUID]}
+		if rs.Rights == nil {
+			rs.Rights = map[string]string{}
+		}
+		state.Roles = append(state.Roles, rs)
+	}
+
+	state.sort()
+	return state, nil
+}
+
+// PlanState computes the changes that are required to bring the server to the desired state.
+// Objects that exist on the server but are not described in the desired state are only deleted if prune is true,
+// and only for the kinds of objects that are listed in the desired state.
+func PlanState(desired *IdmState, prune bool) ([]*StateChange, error) {
+	if err := desired.validate(); err != nil {
+		return nil, err
+	}
+	current, err := ExportState()
+	if err != nil {
+		return nil, err
+	}
+	desired.sort()
+
+	var changes []*StateChange
+
+	// Groups, parents first
+	currentGroups := map[string]*GroupState{}
+	for _, g := range current.Groups {
+		currentGroups[g.Path] = g
+	}
+	for _, g := range desired.Groups {
+		g := g
+		cur, ok := currentGroups[g.Path]
+		if !ok {
+			changes = append(changes, &StateChange{Action: ChangeCreate, Kind: "group", ID: g.Path, apply: func() error {
+				_, e := CreateGroup(g.Path, g.DisplayName)
+				return e
+			}})
+			continue
+		}
+		var details []string
+		diffField(&details, "displayName", cur.DisplayName, g.DisplayName)
+		if len(details) > 0 {
+			changes = append(changes, &StateChange{Action: ChangeUpdate, Kind: "group", ID: g.Path, Details: details, apply: func() error {
+				return updateGroup(g)
+			}})
+		}
+	}
+
+	// Roles, without their rights that are applied once workspaces exist
+	currentRoles := map[string]*RoleState{}
+	for _, r := range current.Roles {
+		currentRoles[r.ID] = r
+	}
+	for _, r := range desired.Roles {
+		r := r
+		cur, ok := currentRoles[r.ID]
+		if !ok {
+			changes = append(changes, &StateChange{Action: ChangeCreate, Kind: "role", ID: r.ID, apply: func() error {
+				_, e := CreateRole(r.Label, r.ID, r.AutoApplies)
+				return e
+			}})
+			continue
+		}
+		var details []string
+		diffField(&details, "label", cur.Label, r.Label)
+		if added, removed := listDiff(cur.AutoApplies, r.AutoApplies); r.AutoApplies != nil && len(added)+len(removed) > 0 {
+			diffList(&details, "autoApplies", cur.AutoApplies, r.AutoApplies)
+		}
+		if len(details) > 0 {
+			changes = append(changes, &StateChange{Action: ChangeUpdate, Kind: "role", ID: r.ID, Details: details, apply: func() error {
+				return updateRole(r)
+			}})
+		}
+	}
+
+	// Workspaces
+	currentWorkspaces := map[string]*WorkspaceState{}
+	for _, ws := range current.Workspaces {
+		currentWorkspaces[ws.Slug] = ws
+	}
+	for _, ws := range desired.Workspaces {
+		ws := ws
+		cur, ok := currentWorkspaces[ws.Slug]
+		if !ok {
+			changes = append(changes, &StateChange{Action: ChangeCreate, Kind: "workspace", ID: ws.Slug, apply: func() error {
+				return saveWorkspaceState(ws, true, true)
+			}})
+			continue
+		}
+		var details []string
+		diffField(&details, "label", cur.Label, ws.Label)
+		diffField(&details, "description", cur.Description, ws.Description)
+		rootsChanged := false
+		if ws.Roots != nil {
+			same, e := sameRoots(cur.Roots, ws.Roots)
+			if e != nil {
+				return nil, e
+			}
+			if !same {
+				rootsChanged = true
+				diffList(&details, "roots", cur.Roots, ws.Roots)
+			}
+		}
+		rightsChanged := false
+		if ws.DefaultRights != nil {
+			var curRights string
+			if cur.DefaultRights != nil {
+				curRights = *cur.DefaultRights
+			}
+			if curRights != *ws.DefaultRights {
+				rightsChanged = true
+				details = append(details, fmt.Sprintf("defaultRights: %q -> %q", curRights, *ws.DefaultRights))
+			}
+		}
+		if len(details) > 0 {
+			changes = append(changes, &StateChange{Action: ChangeUpdate, Kind: "workspace", ID: ws.Slug, Details: details, apply: func() error {
+				return saveWorkspaceState(ws, rootsChanged, rightsChanged)
+			}})
+		}
+	}
+
+	// Users, once their groups and roles exist
+	currentUsers := map[string]*UserState{}
+	for _, u := range current.Users {
+		currentUsers[u.Login] = u
+	}
+	for _, u := range desired.Users {
+		u := u
+		cur, ok := currentUsers[u.Login]
+		if !ok {
+			changes = append(changes, &StateChange{Action: ChangeCreate, Kind: "user", ID: u.Login, apply: func() error {
+				return createUserState(u)
+			}})
+			continue
+		}
+		var details []string
+		diffField(&details, "displayName", cur.DisplayName, u.DisplayName)
+		diffField(&details, "email", cur.Email, u.Email)
+		if u.GroupPath != "" {
+			diffField(&details, "groupPath", cur.GroupPath, path.Join("/", u.GroupPath))
+		}
+		diffField(&details, "profile", cur.Profile, u.Profile)
+		if u.Locked != nil && *u.Locked != *cur.Locked {
+			details = append(details, fmt.Sprintf("locked: %t -> %t", *cur.Locked, *u.Locked))
+		}
+		var added, removed []string
+		if u.Roles != nil {
+			added, removed = listDiff(cur.Roles, u.Roles)
+			if len(added)+len(removed) > 0 {
+				diffList(&details, "roles", cur.Roles, u.Roles)
+			}
+		}
+		if len(details) > 0 {
+			changes = append(changes, &StateChange{Action: ChangeUpdate, Kind: "user", ID: u.Login, Details: details, apply: func() error {
+				return updateUserState(u, added, removed)
+			}})
+		}
+	}
+
+	// Rights of the roles on workspaces
+	for _, r := range desired.Roles {
+		if r.Rights == nil {
+			continue
+		}
+		curRights := map[string]string{}
+		if cur, ok := currentRoles[r.ID]; ok {
+			curRights = cur.Rights
+		}
+		var slugs []string
+		for slug := range r.Rights {
+			slugs = append(slugs, slug)
+		}
+		for slug := range curRights {
+			if _, ok := r.Rights[slug]; !ok {
+				slugs = append(slugs, slug)
+			}
+		}
+		sort.Strings(slugs)
+		for _, slug := range slugs {
+			roleID, slug := r.ID, slug
+			from, to := curRights[slug], normalizeRights(r.Rights[slug])
+			if from == to {
+				continue
+			}
+			action := ChangeUpdate
+			if from == "" {
+				action = ChangeCreate
+			} else if to == "" {
+				action = ChangeDelete
+			}
+			changes = append(changes, &StateChange{
+				Action:  action,
+				Kind:    "rights",
+				ID:      roleID + " on " + slug,
+				Details: []string{fmt.Sprintf("%q -> %q", from, to)},
+				apply: func() error {
+					return SetWorkspaceRights(roleID, slug, to)
+				},
+			})
+		}
+	}
+
+	if !prune {
+		return changes, nil
+	}
+
+	// The current user must never be deleted: DefaultConfig.User is empty when the session relies on a token
+	self, err := RetrieveCurrentSessionLogin()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve the current user, refusing to prune: %s", err.Error())
+	}
+	if self == "" {
+		return nil, fmt.Errorf("could not retrieve the current user, refusing to prune")
+	}
+
+	// Deletions: users first, then groups from the deepest ones, workspaces and roles
+	if desired.Users != nil {
+		wanted := map[string]bool{}
+		for _, u := range desired.Users {
+			wanted[u.Login] = true
+		}
+		for _, u := range current.Users {
+			login := u.Login
+			if wanted[login] || login == self {
+				continue
+			}
+			changes = append(changes, &StateChange{Action: ChangeDelete, Kind: "user", ID: login, apply: func() error {
+				return DeleteUser(login)
+			}})
+		}
+	}
+	if desired.Groups != nil {
+		wanted := map[string]bool{}
+		for _, g := range desired.Groups {
+			wanted[g.Path] = true
+		}
+		for i := len(current.Groups) - 1; i >= 0; i-- {
+			p := current.Groups[i].Path
+			if wanted[p] {
+				continue
+			}
+			changes = append(changes, &StateChange{Action: ChangeDelete, Kind: "group", ID: p, apply: func() error {
+				return DeleteGroup(p)
+			}})
+		}
+	}
+	if desired.Workspaces != nil {
+		wanted := map[string]bool{}
+		for _, slug := range protectedWorkspaces {
+			wanted[slug] = true
+		}
+		for _, ws := range desired.Workspaces {
+			wanted[ws.Slug] = true
+		}
+		for _, ws := range current.Workspaces {
+			slug := ws.Slug
+			if wanted[slug] {
+				continue
+			}
+			changes = append(changes, &StateChange{Action: ChangeDelete, Kind: "workspace", ID: slug, apply: func() error {
+				return DeleteWorkspace(slug)
+			}})
+		}
+	}
+	if desired.Roles != nil {
+		wanted := map[string]bool{}
+		for _, id := range protectedRoles {
+			wanted[id] = true
+		}
+		for _, r := range desired.Roles {
+			wanted[r.ID] = true
+		}
+		for _, r := range current.Roles {
+			id := r.ID
+			if wanted[id] {
+				continue
+			}
+			changes = append(changes, &StateChange{Action: ChangeDelete, Kind: "role", ID: id, apply: func() error {
+				return DeleteRole(id)
+			}})
+		}
+	}
+	return changes, nil
+}
+
+func (s *IdmState) sort() {
+	sort.Slice(s.Groups, func(i, j int) bool { return s.Groups[i].Path < s.Groups[j].Path })
+	sort.Slice(s.Users, func(i, j int) bool { return s.Users[i].Login < s.Users[j].Login })
+	sort.Slice(s.Roles, func(i, j int) bool { return s.Roles[i].ID < s.Roles[j].ID })
+	sort.Slice(s.Workspaces, func(i, j int) bool { return s.Workspaces[i].Slug < s.Workspaces[j].Slug })
+}
+
+// validate checks that all objects have an identifier that is only used once, and normalizes group paths.
+func (s *IdmState) validate() error {
+	seen := map[string]bool{}
+	check := func(kind, id string) error {
+		if id == "" {
+			return fmt.Errorf("a %s has no identifier", kind)
+		}
+		if seen[kind+":"+id] {
+			return fmt.Errorf("%s %s is defined more than once", kind, id)
+		}
+		seen[kind+":"+id] = true
+		return nil
+	}
+	for _, g := range s.Groups {
+		g.Path = path.Join("/", g.Path)
+		if err := check("group", strings.Trim(g.Path, "/")); err != nil {
+			return err
+		}
+	}
+	for _, u := range s.Users {
+		if err := check("user", u.Login); err != nil {
+			return err
+		}
+		if u.Profile != "" && !isValidProfile(u.Profile) {
+			return fmt.Errorf("invalid profile %s for user %s, expected one of %s", u.Profile, u.Login, strings.Join(UserProfiles, ", "))
+		}
+	}
+	for _, r := range s.Roles {
+		if err := check("role", r.ID); err != nil {
+			return err
+		}
+		for slug, rights := range r.Rights {
+			if n := normalizeRights(rights); n != "" && n != "r" && n != "w" && n != "rw" && n != AclDeny {
+				return fmt.Errorf("invalid rights %s for role %s on %s, expected r, w, rw, deny or none", rights, r.ID, slug)
+			}
+		}
+	}
+	for _, ws := range s.Workspaces {
+		if err := check("workspace", ws.Slug); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignedRoles lists the roles that have been explicitly assigned to a user: technical roles
+// and roles that are automatically applied to the profile of the user are left aside.
+func assignedRoles(u *models.IdmUser, roles map[string]*models.IdmRole) []string {
+	ids := []string{}
+	profile := u.Attributes[UserAttrProfile]
+	for _, ur := range u.Roles {
+		r, ok := roles[ur.UUID]
+		if !ok || r.UserRole || r.GroupRole || r.UUID == RootGroupRole {
+			continue
+		}
+		auto := false
+		for _, p := range r.AutoApplies {
+			if p == profile {
+				auto = true
+			}
+		}
+		if !auto {
+			ids = append(ids, r.UUID)
+		}
+	}
+	return ids
+}
+
+// workspaceRights retrieves the rights that each role grants on the passed workspaces, indexed by role ID and workspace slug.
+func workspaceRights(slugs map[string]string) (map[string]map[string]string, error) {
+	rights := map[string]map[string]string{}
+	if len(slugs) == 0 {
+		return rights, nil
+	}
+	var wsIDs []string
+	for id := range slugs {
+		wsIDs = append(wsIDs, id)
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &acl_service.SearchAclsParams{
+		Body: &models.RestSearchACLRequest{
+			Queries: []*models.IdmACLSingleQuery{{
+				WorkspaceIDs: wsIDs,
+				Actions:      []*models.IdmACLAction{{Name: AclRead}, {Name: AclWrite}, {Name: AclDeny}},
+			}},
+		},
+		Context: ctx,
+	}
+	res, err := client.ACLService.SearchAcls(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list rights on workspaces: %s", err.Error())
+	}
+	for _, acl := range res.Payload.ACLs {
+		slug, ok := slugs[acl.WorkspaceID]
+		if !ok || acl.Action == nil || acl.RoleID == "" {
+			continue
+		}
+		if rights[acl.RoleID] == nil {
+			rights[acl.RoleID] = map[string]string{}
+		}
+		current := rights[acl.RoleID][slug]
+		switch acl.Action.Name {
+		case AclRead:
+			current += "r"
+		case AclWrite:
+			current += "w"
+		case AclDeny:
+			current = AclDeny
+		}
+		if current != AclDeny {
+			current = normalizeRights(current)
+		}
+		rights[acl.RoleID][slug] = current
+	}
+	return rights, nil
+}
+
+// normalizeRights returns the canonical form of rights: r, w, rw, deny, or empty for no rights.
+func normalizeRights(rights string) string {
+	switch rights {
+	case "", "none":
+		return ""
+	case AclDeny:
+		return AclDeny
+	}
+	var n string
+	if strings.Contains(rights, "r") {
+		n += "r"
+	}
+	if strings.Contains(rights, "w") {
+		n += "w"
+	}
+	if strings.Trim(rights, "rw") != "" {
+		// Invalid rights are returned as is, so that they are rejected
+		return rights
+	}
+	return n
+}
+
+func listAdminWorkspaces() ([]*models.IdmWorkspace, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &workspace_service.SearchWorkspacesParams{
+		Body: &models.RestSearchWorkspaceRequest{
+			Queries: []*models.IdmWorkspaceSingleQuery{{Scope: models.NewIdmWorkspaceScope(models.IdmWorkspaceScopeADMIN)}},
+		},
+		Context: ctx,
+	}
+	res, err := client.WorkspaceService.SearchWorkspaces(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list workspaces: %s", err.Error())
+	}
+	return res.Payload.Workspaces, nil
+}
+
+func listTemplatePaths() (map[string]bool, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.ConfigService.ListVirtualNodes(&config_service.ListVirtualNodesParams{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("could not list template paths: %s", err.Error())
+	}
+	templates := map[string]bool{}
+	for _, n := range res.Payload.Children {
+		templates[n.UUID] = true
+	}
+	return templates, nil
+}
+
+// sameRoots checks if two lists of root definitions designate the same nodes.
+func sameRoots(current, desired []string) (bool, error) {
+	if len(current) != len(desired) {
+		return false, nil
+	}
+	ids := map[string]bool{}
+	for _, lists := range [][]string{current, desired} {
+		for _, r := range lists {
+			n, err := ResolveWorkspaceRoot(r)
+			if err != nil {
+				return false, err
+			}
+			ids[n.UUID] = true
+		}
+	}
+	return len(ids) == len(current), nil
+}
+
+func updateGroup(gs *GroupState) error {
+	g, err := FindGroup(gs.Path)
+	if err != nil {
+		return err
+	}
+	if g.Attributes == nil {
+		g.Attributes = map[string]string{}
+	}
+	g.Attributes[UserAttrDisplayName] = gs.DisplayName
+	_, err = saveGroup(g, GroupFullPath(g))
+	return err
+}
+
+func updateRole(rs *RoleState) error {
+	r, err := FindRole(rs.ID)
+	if err != nil {
+		return err
+	}
+	if rs.Label != "" {
+		r.Label = rs.Label
+	}
+	if rs.AutoApplies != nil {
+		r.AutoApplies = rs.AutoApplies
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	if _, err = client.RoleService.SetRole(&role_service.SetRoleParams{UUID: r.UUID, Body: r, Context: ctx}); err != nil {
+		return fmt.Errorf("could not update role %s: %s", rs.ID, err.Error())
+	}
+	return nil
+}
+
+func saveWorkspaceState(wss *WorkspaceState, setRoots, setRights bool) error {
+	ws, err := FindWorkspace(wss.Slug)
+	if err != nil {
+		// This is a new workspace
+		if len(wss.Roots) == 0 {
+			return fmt.Errorf("workspace %s has no root", wss.Slug)
+		}
+		ws = &models.IdmWorkspace{
+			Slug:  wss.Slug,
+			Label: wss.Slug,
+			Scope: models.NewIdmWorkspaceScope(models.IdmWorkspaceScopeADMIN),
+		}
+		SetWorkspaceAttribute(ws, WsAttrAllowSync, true)
+	}
+	if wss.Label != "" {
+		ws.Label = wss.Label
+	}
+	if wss.Description != "" {
+		ws.Description = wss.Description
+	}
+	if setRoots {
+		if err = SetWorkspaceRoots(ws, wss.Roots); err != nil {
+			return err
+		}
+	}
+	var rights *string
+	if setRights {
+		rights = wss.DefaultRights
+	}
+	_, err = SaveWorkspace(ws, rights)
+	return err
+}
+
+func createUserState(us *UserState) error {
+	_, err := CreateUser(&UserDefinition{
+		Login:       us.Login,
+		Password:    us.Password,
+		DisplayName: us.DisplayName,
+		Email:       us.Email,
+		GroupPath:   us.GroupPath,
+		Profile:     us.Profile,
+	})
+	if err != nil {
+		return err
+	}
+	return updateUserState(us, us.Roles, nil)
+}
+
+func updateUserState(us *UserState, addedRoles, removedRoles []string) error {
+	_, err := UpdateUser(&UserDefinition{
+		Login:       us.Login,
+		DisplayName: us.DisplayName,
+		Email:       us.Email,
+		GroupPath:   us.GroupPath,
+		Profile:     us.Profile,
+	})
+	if err != nil {
+		return err
+	}
+	if us.Locked != nil {
+		u, err := FindUser(us.Login)
+		if err != nil {
+			return err
+		}
+		if IsUserLocked(u) != *us.Locked {
+			if err = SetUserLocked(us.Login, *us.Locked); err != nil {
+				return err
+			}
+		}
+	}
+	for _, list := range []struct {
+		ids    []string
+		assign bool
+	}{{removedRoles, false}, {addedRoles, true}} {
+		for _, id := range list.ids {
+			r, err := FindRole(id)
+			if err != nil {
+				return err
+			}
+			if err = AssignRoleToUser(r, us.Login, list.assign); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func diffField(details *[]string, name, current, desired string) {
+	if desired != "" && desired != current {
+		*details = append(*details, fmt.Sprintf("%s: %q -> %q", name, current, desired))
+	}
+}
+
+func diffList(details *[]string, name string, current, desired []string) {
+	*details = append(*details, fmt.Sprintf("%s: [%s] -> [%s]", name, strings.Join(current, ", "), strings.Join(desired, ", ")))
+}
+
+// listDiff returns the values that are in desired but not in current, and the ones that are in current but not in desired.
+func listDiff(current, desired []string) (added, removed []string) {
+	cur := map[string]bool{}
+	for _, v := range current {
+		cur[v] = true
+	}
+	des := map[string]bool{}
+	for _, v := range desired {
+		des[v] = true
+		if !cur[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range current {
+		if !des[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
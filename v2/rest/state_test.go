@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListDiff(t *testing.T) {
+	tests := []struct {
+		current, desired []string
+		added, removed   []string
+	}{
+		{current: nil, desired: nil},
+		{current: []string{"a", "b"}, desired: []string{"b", "a"}},
+		{current: nil, desired: []string{"a", "b"}, added: []string{"a", "b"}},
+		{current: []string{"a", "b"}, desired: nil, removed: []string{"a", "b"}},
+		{current: []string{"a", "b"}, desired: []string{"b", "c"}, added: []string{"c"}, removed: []string{"a"}},
+	}
+	for _, tt := range tests {
+		added, removed := listDiff(tt.current, tt.desired)
+		if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(removed, tt.removed) {
+			t.Errorf("listDiff(%v, %v) = %v, %v, want %v, %v", tt.current, tt.desired, added, removed, tt.added, tt.removed)
+		}
+	}
+}
+
+func TestNormalizeRights(t *testing.T) {
+	tests := []struct {
+		rights string
+		want   string
+	}{
+		{"", ""},
+		{"none", ""},
+		{"r", "r"},
+		{"w", "w"},
+		{"rw", "rw"},
+		{"wr", "rw"},
+		{"rwr", "rw"},
+		{AclDeny, AclDeny},
+		// Invalid rights are returned as is
+		{"rx", "rx"},
+		{"read", "read"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRights(tt.rights); got != tt.want {
+			t.Errorf("normalizeRights(%q) = %q, want %q", tt.rights, got, tt.want)
+		}
+	}
+}
+
+func TestValidateState(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   *IdmState
+		wantErr string
+	}{
+		{
+			name: "valid",
+			state: &IdmState{
+				Groups:     []*GroupState{{Path: "engineering"}, {Path: "/engineering/backend/"}},
+				Users:      []*UserState{{Login: "alice", Profile: "standard"}, {Login: "bob"}},
+				Roles:      []*RoleState{{ID: "project-x", Rights: map[string]string{"a": "rw", "b": "wr", "c": AclDeny, "d": "none"}}},
+				Workspaces: []*WorkspaceState{{Slug: "a"}},
+			},
+		},
+		{
+			name:    "empty group",
+			state:   &IdmState{Groups: []*GroupState{{Path: "/"}}},
+			wantErr: "a group has no identifier",
+		},
+		{
+			name:    "duplicated group",
+			state:   &IdmState{Groups: []*GroupState{{Path: "engineering"}, {Path: "/engineering/"}}},
+			wantErr: "group engineering is defined more than once",
+		},
+		{
+			name:    "duplicated user",
+			state:   &IdmState{Users: []*UserState{{Login: "alice"}, {Login: "alice"}}},
+			wantErr: "user alice is defined more than once",
+		},
+		{
+			name:    "invalid profile",
+			state:   &IdmState{Users: []*UserState{{Login: "alice", Profile: "superuser"}}},
+			wantErr: "invalid profile superuser",
+		},
+		{
+			name:    "role without ID",
+			state:   &IdmState{Roles: []*RoleState{{Label: "No ID"}}},
+			wantErr: "a role has no identifier",
+		},
+		{
+			name:    "invalid rights",
+			state:   &IdmState{Roles: []*RoleState{{ID: "project-x", Rights: map[string]string{"a": "read"}}}},
+			wantErr: "invalid rights read",
+		},
+		{
+			name:    "duplicated workspace",
+			state:   &IdmState{Workspaces: []*WorkspaceState{{Slug: "a"}, {Slug: "a"}}},
+			wantErr: "workspace a is defined more than once",
+		},
+		{
+			// Identifiers only have to be unique within a kind
+			name:  "same identifier for different kinds",
+			state: &IdmState{Roles: []*RoleState{{ID: "a"}}, Workspaces: []*WorkspaceState{{Slug: "a"}}},
+		},
+	}
+	for _, tt := range tests {
+		err := tt.state.validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateStateNormalizesGroupPaths(t *testing.T) {
+	state := &IdmState{Groups: []*GroupState{{Path: "engineering/backend/"}}}
+	if err := state.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Groups[0].Path; got != "/engineering/backend" {
+		t.Errorf("group path = %q, want %q", got, "/engineering/backend")
+	}
+}
@@ -0,0 +1,228 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pydio/cells-sdk-go/v3/client/token_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// RevokeToken invalidates the passed token on the server: it cannot be used anymore to authenticate.
+func RevokeToken(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &token_service.RevokeParams{
+		Body:    &models.RestRevokeRequest{TokenID: token},
+		Context: ctx,
+	}
+	res, err := client.TokenService.Revoke(params)
+	if err != nil {
+		return fmt.Errorf("could not revoke token: %s", err.Error())
+	}
+	if !res.Payload.Success {
+		return fmt.Errorf("token has not been revoked: %s", res.Payload.Message)
+	}
+	return nil
+}
+
+// personalTokensURI is the endpoint of the token service that generates and lists personal access tokens.
+const personalTokensURI = "/a/auth/token/personal"
+
+// patTypePersonal is the type of the tokens that are generated for a user, as opposed to the tokens of documents.
+const patTypePersonal = "PERSONAL"
+
+// PersonalToken describes a personal access token. Its value is only returned when it is generated.
+type PersonalToken struct {
+	UUID      string    `json:"Uuid"`
+	Label     string    `json:"Label"`
+	UserLogin string    `json:"UserLogin"`
+	Scopes    []string  `json:"Scopes,omitempty"`
+	ExpiresAt timestamp `json:"ExpiresAt,omitempty"`
+	CreatedAt timestamp `json:"CreatedAt,omitempty"`
+	CreatedBy string    `json:"CreatedBy,omitempty"`
+}
+
+// timestamp is a number of seconds since the epoch, that the server sends either as a number or as a string.
+type timestamp int64
+
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), "\"")
+	if s == "" || s == "null" {
+		*t = 0
+		return nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", string(data))
+	}
+	*t = timestamp(i)
+	return nil
+}
+
+// Time returns the zero time if no timestamp is defined.
+func (t timestamp) Time() time.Time {
+	if t <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+// GeneratePersonalToken creates a personal access token for the current user and returns its value,
+// that cannot be retrieved afterwards. If expiresAt is zero, the token does not expire. If no scope is given,
+// the token grants the same rights as the user.
+func GeneratePersonalToken(label string, expiresAt time.Time, scopes []string) (string, error) {
+	if strings.TrimSpace(label) == "" {
+		return "", fmt.Errorf("please provide a label for the token")
+	}
+	login, err := RetrieveCurrentSessionLogin()
+	if err != nil {
+		return "", err
+	}
+	req := map[string]interface{}{
+		"Type":      patTypePersonal,
+		"UserLogin": login,
+		"Label":     label,
+		"Scopes":    scopes,
+	}
+	if !expiresAt.IsZero() {
+		if time.Until(expiresAt) <= 0 {
+			return "", fmt.Errorf("expiration date %s is in the past", expiresAt.Format(time.RFC3339))
+		}
+		req["ExpiresAt"] = strconv.FormatInt(expiresAt.Unix(), 10)
+	}
+	var res struct {
+		AccessToken string
+		TokenUuid   string
+	}
+	if err = tokenServiceCall(http.MethodPost, personalTokensURI, req, &res); err != nil {
+		return "", fmt.Errorf("could not generate token: %s", err.Error())
+	}
+	if res.AccessToken == "" {
+		return "", fmt.Errorf("could not generate token: the server did not return any token")
+	}
+	return res.AccessToken, nil
+}
+
+// ListPersonalTokens retrieves the personal access tokens of the current user.
+func ListPersonalTokens() ([]*PersonalToken, error) {
+	login, err := RetrieveCurrentSessionLogin()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("Type", patTypePersonal)
+	query.Set("ByUserLogin", login)
+	var res struct {
+		Tokens []*PersonalToken
+	}
+	if err = tokenServiceCall(http.MethodGet, personalTokensURI+"?"+query.Encode(), nil, &res); err != nil {
+		return nil, fmt.Errorf("could not list tokens: %s", err.Error())
+	}
+	return res.Tokens, nil
+}
+
+// tokenServiceCall sends an authenticated JSON request to the token service and decodes the response in result.
+func tokenServiceCall(method, uri string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, DefaultConfig.Url+uri, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := AuthenticatedRequest(req, DefaultConfig)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status code %d - %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// TokenStatus describes the token that is used to authenticate with a config.
+type TokenStatus struct {
+	AuthType string
+	// ExpiresAt is zero when the expiration of the token is unknown, e.g. for personal access tokens.
+	ExpiresAt       time.Time
+	HasRefreshToken bool
+	// Scopes are only known when the token is a JWT, they are otherwise empty.
+	Scopes []string
+}
+
+// Remaining returns the time before the token expires, which is negative once it has expired.
+func (t *TokenStatus) Remaining() time.Duration {
+	return time.Until(t.ExpiresAt)
+}
+
+// GetTokenStatus inspects the token of the passed config, without sending any request to the server.
+func GetTokenStatus(c *CecConfig) *TokenStatus {
+	idToken, refreshToken, expiresAt := c.tokens()
+	st := &TokenStatus{AuthType: c.AuthType, HasRefreshToken: refreshToken != ""}
+	if expiresAt > 0 {
+		st.ExpiresAt = time.Unix(int64(expiresAt), 0)
+	}
+	claims := jwtClaims(idToken)
+	if claims == nil {
+		return st
+	}
+	if exp, ok := claims["exp"].(float64); ok && st.ExpiresAt.IsZero() {
+		st.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	for _, name := range []string{"scope", "scp"} {
+		switch v := claims[name].(type) {
+		case string:
+			st.Scopes = strings.Fields(v)
+		case []interface{}:
+			for _, sc := range v {
+				if str, ok := sc.(string); ok {
+					st.Scopes = append(st.Scopes, str)
+				}
+			}
+		}
+	}
+	return st
+}
+
+// jwtClaims decodes the claims of the passed token without verifying it, it returns nil if it is not a JWT.
+func jwtClaims(token string) map[string]interface{} {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	claims := map[string]interface{}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPersonalTokens(t *testing.T) {
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	var generated map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-0" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/a/frontend/state":
+			_, _ = w.Write([]byte(`<tree><user id="alice"><repositories/></user></tree>`))
+		case r.Method == http.MethodPost && r.URL.Path == personalTokensURI:
+			if err := json.NewDecoder(r.Body).Decode(&generated); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"AccessToken":"pat-1","TokenUuid":"uuid-1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == personalTokensURI:
+			if r.URL.Query().Get("ByUserLogin") != "alice" || r.URL.Query().Get("Type") != patTypePersonal {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// Timestamps are either numbers or strings
+			_, _ = w.Write([]byte(`{"Tokens":[
+				{"Uuid":"uuid-1","Label":"ci","UserLogin":"alice","Scopes":["rest:read"],"ExpiresAt":"1700000000","CreatedAt":1690000000},
+				{"Uuid":"uuid-2","Label":"laptop","UserLogin":"alice"}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		}
+	}))
+	defer srv.Close()
+	setUpApiClient(t, srv.URL)
+
+	value, err := GeneratePersonalToken("ci", expiresAt, []string{"rest:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "pat-1" {
+		t.Errorf("GeneratePersonalToken() = %q, want pat-1", value)
+	}
+	want := map[string]interface{}{
+		"Type":      patTypePersonal,
+		"UserLogin": "alice",
+		"Label":     "ci",
+		"Scopes":    []interface{}{"rest:read"},
+		"ExpiresAt": strconv.FormatInt(expiresAt.Unix(), 10),
+	}
+	if !reflect.DeepEqual(generated, want) {
+		t.Errorf("generate request = %v, want %v", generated, want)
+	}
+
+	if _, err = GeneratePersonalToken("", time.Time{}, nil); err == nil {
+		t.Error("expected an error for a token without label")
+	}
+	if _, err = GeneratePersonalToken("old", time.Now().Add(-time.Hour), nil); err == nil {
+		t.Error("expected an error for a token that has already expired")
+	}
+
+	tokens, err := ListPersonalTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("ListPersonalTokens() returned %d tokens, want 2", len(tokens))
+	}
+	if tk := tokens[0]; tk.UUID != "uuid-1" || tk.Label != "ci" || tk.ExpiresAt.Time().Unix() != 1700000000 || tk.CreatedAt.Time().Unix() != 1690000000 {
+		t.Errorf("unexpected first token %+v", tk)
+	}
+	if tk := tokens[1]; !tk.ExpiresAt.Time().IsZero() {
+		t.Errorf("token without expiration expires at %s", tk.ExpiresAt.Time())
+	}
+}
@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	openapiruntime "github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+)
+
+// TransportSettings defines how to reach the server of a profile when it is not directly accessible
+// with the default settings of the system, e.g. behind an internal CA or a corporate proxy.
+type TransportSettings struct {
+	// CAFile is the path to a PEM bundle of certificate authorities that are trusted in addition to the system ones.
+	CAFile string `json:"caFile,omitempty"`
+	// ClientCertFile and ClientKeyFile are the paths to the PEM certificate and key that are used for mutual TLS.
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+	ClientKeyFile  string `json:"clientKeyFile,omitempty"`
+	// ProxyURL is the URL of the HTTP proxy to use. If empty, the HTTPS_PROXY and HTTP_PROXY variables are used.
+	ProxyURL string `json:"proxyUrl,omitempty"`
+}
+
+// NewHTTPTransport creates a transport that applies the TLS and proxy settings of the passed config.
+func NewHTTPTransport(c *CecConfig) (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.SkipVerify}
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file %s: %s", c.CAFile, err.Error())
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificate found in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	tr.TLSClientConfig = tlsConfig
+
+	if c.ProxyURL != "" {
+		proxy, err := url.Parse(c.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("proxy URL %s is not valid", c.ProxyURL)
+		}
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+	return tr, nil
+}
+
+// NewHTTPClient creates an HTTP client that applies the TLS and proxy settings of the passed config.
+func NewHTTPClient(c *CecConfig) (*http.Client, error) {
+	tr, err := NewHTTPTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: tr}, nil
+}
+
+// profileTransport adds the custom headers of a profile, and its bearer token if a token provider is set,
+// to the requests that are sent with the TLS and proxy settings of the profile.
+type profileTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+	tp      tokenProvider
+}
+
+// tokenProvider provides the bearer token of the requests.
+type tokenProvider interface {
+	Retrieve() (string, error)
+}
+
+func (t *profileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A round tripper must not modify the passed request
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if t.tp != nil {
+		token, err := t.tp.Retrieve()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newProfileClient creates an HTTP client that sends requests with the transport settings and the custom headers
+// of the passed config. If tp is not nil, requests are also authenticated with its bearer token.
+func newProfileClient(c *CecConfig, tp tokenProvider) (*http.Client, error) {
+	tr, err := NewHTTPTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: &profileTransport{base: tr, headers: c.CustomHeaders, tp: tp}}, nil
+}
+
+// apiBasePath is the path of the REST API on the server.
+const apiBasePath = "/a"
+
+// newRestTransport creates the transport of the REST API client: requests are sent with the transport settings
+// and the custom headers of the passed config and, unless anonymous is true, with its token.
+func newRestTransport(c *CecConfig, anonymous bool) (context.Context, openapiruntime.ClientTransport, error) {
+	u, err := url.Parse(c.Url)
+	if err != nil || u.Host == "" {
+		return nil, nil, fmt.Errorf("server URL %s is not valid", c.Url)
+	}
+	var tp tokenProvider
+	if !anonymous {
+		if tp, err = newTokenProvider(c); err != nil {
+			return nil, nil, err
+		}
+	}
+	httpClient, err := newProfileClient(c, tp)
+	if err != nil {
+		return nil, nil, err
+	}
+	basePath := strings.TrimSuffix(u.Path, "/") + apiBasePath
+	return context.Background(), httptransport.NewWithClient(u.Host, basePath, []string{u.Scheme}, httpClient), nil
+}
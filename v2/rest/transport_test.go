@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	cells_sdk "github.com/pydio/cells-sdk-go/v3"
+)
+
+func TestProfileClientUsesProfileProxy(t *testing.T) {
+	var mux sync.Mutex
+	var proxied []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		proxied = append(proxied, r)
+		mux.Unlock()
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	original := http.DefaultTransport
+	c := &CecConfig{
+		SdkConfig: cells_sdk.SdkConfig{
+			Url:           "http://cells.example.com",
+			CustomHeaders: map[string]string{"User-Agent": "cells-client/test"},
+		},
+		TransportSettings: TransportSettings{ProxyURL: proxy.URL},
+	}
+	client, err := newProfileClient(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = newRestTransport(c, true); err != nil {
+		t.Fatal(err)
+	}
+	if http.DefaultTransport != original {
+		t.Fatal("the default transport of the process has been replaced")
+	}
+
+	resp, err := client.Get(c.Url + "/a/frontend/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "proxied" {
+		t.Fatalf("request has not been sent through the proxy of the profile: %q", body)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	if len(proxied) != 1 {
+		t.Fatalf("proxy received %d requests, want 1", len(proxied))
+	}
+	r := proxied[0]
+	if r.URL.String() != c.Url+"/a/frontend/state" {
+		t.Errorf("proxy received a request for %s", r.URL)
+	}
+	if ua := r.Header.Get("User-Agent"); ua != "cells-client/test" {
+		t.Errorf("User-Agent = %q, want the custom header of the profile", ua)
+	}
+}
+
+func TestNewRestTransportRejectsInvalidURL(t *testing.T) {
+	if _, _, err := newRestTransport(&CecConfig{SdkConfig: cells_sdk.SdkConfig{Url: "not a url"}}, true); err == nil {
+		t.Error("expected an error for an invalid server URL")
+	}
+}
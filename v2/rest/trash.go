@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/tree_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+const (
+	// RecycleBinName is the name of the folder that is at the root of each workspace and stores the trashed nodes.
+	RecycleBinName = "recycle_bin"
+	// MetaRecycleRestore is the key of the original location of a trashed node in its MetaStore.
+	MetaRecycleRestore = "recycle_restore"
+)
+
+// TrashedNode is a node that has been moved to the recycle bin of a workspace.
+type TrashedNode struct {
+	Node         *models.TreeNode
+	Workspace    string
+	OriginalPath string
+}
+
+// ListWorkspaceSlugs retrieves the slugs of all workspaces that are accessible to the current user.
+func ListWorkspaceSlugs() ([]string, error) {
+	nodes, err := listChildren("")
+	if err != nil {
+		return nil, fmt.Errorf("could not list workspaces: %s", err.Error())
+	}
+	var slugs []string
+	for _, n := range nodes {
+		if slug := strings.Trim(n.Path, "/"); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs, nil
+}
+
+// ListTrash retrieves the nodes that are in the recycle bin of the passed workspace,
+// or in the recycle bins of all accessible workspaces if no workspace is given.
+func ListTrash(workspace string) ([]*TrashedNode, error) {
+	slugs := []string{strings.Trim(workspace, "/")}
+	if slugs[0] == "" {
+		var err error
+		if slugs, err = ListWorkspaceSlugs(); err != nil {
+			return nil, err
+		}
+	}
+
+	var trashed []*TrashedNode
+	for _, slug := range slugs {
+		binPath := path.Join(slug, RecycleBinName)
+		if _, ok := StatNode(binPath); !ok {
+			// Some workspaces (e.g. cells or read-only workspaces) have no recycle bin
+			continue
+		}
+		nodes, err := listChildren(binPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not list recycle bin of %s: %s", slug, err.Error())
+		}
+		for _, n := range nodes {
+			trashed = append(trashed, &TrashedNode{
+				Node:         n,
+				Workspace:    slug,
+				OriginalPath: strings.Trim(n.MetaStore[MetaRecycleRestore], "\""),
+			})
+		}
+	}
+	return trashed, nil
+}
+
+// IsInTrash checks if the passed path points to a node that is directly in the recycle bin of a workspace.
+func IsInTrash(nodePath string) bool {
+	parts := splitPath(nodePath)
+	return len(parts) == 3 && parts[1] == RecycleBinName
+}
+
+// RestoreNodes launches the jobs that move the passed trashed nodes back to their original location.
+func RestoreNodes(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no paths found to restore")
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	var nn []*models.TreeNode
+	for _, p := range paths {
+		nn = append(nn, &models.TreeNode{Path: p})
+	}
+
+	params := tree_service.NewRestoreNodesParams()
+	params.SetContext(ctx)
+	params.Body = &models.RestRestoreNodesRequest{
+		Nodes: nn,
+	}
+	res, err := client.TreeService.RestoreNodes(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not restore nodes: %s", err.Error())
+	}
+
+	var jobUUIDs []string
+	for _, job := range res.Payload.RestoreJobs {
+		jobUUIDs = append(jobUUIDs, job.UUID)
+	}
+	return jobUUIDs, nil
+}
@@ -0,0 +1,268 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/user_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// Well-known attributes of the users.
+const (
+	UserAttrDisplayName = "displayName"
+	UserAttrEmail       = "email"
+	UserAttrProfile     = "profile"
+	UserAttrLocks       = "locks"
+
+	// userLockLogout is the lock that prevents a user from logging in.
+	userLockLogout = "logout"
+)
+
+// UserProfiles lists the valid values for the profile attribute of a user.
+var UserProfiles = []string{"standard", "shared", "admin"}
+
+// UserDefinition holds the settings of a user, as they are passed on the command line or in an import file.
+type UserDefinition struct {
+	Login       string `json:"login"`
+	Password    string `json:"password,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	GroupPath   string `json:"groupPath,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+}
+
+// Apply copies the non-empty settings of the definition to the passed user.
+func (d *UserDefinition) Apply(u *models.IdmUser) error {
+	if u.Attributes == nil {
+		u.Attributes = map[string]string{}
+	}
+	if d.Password != "" {
+		u.Password = d.Password
+	}
+	if d.DisplayName != "" {
+		u.Attributes[UserAttrDisplayName] = d.DisplayName
+	}
+	if d.Email != "" {
+		u.Attributes[UserAttrEmail] = d.Email
+	}
+	if d.GroupPath != "" {
+		u.GroupPath = "/" + strings.Trim(d.GroupPath, "/")
+	}
+	if d.Profile != "" {
+		if !isValidProfile(d.Profile) {
+			return fmt.Errorf("invalid profile %s, expected one of %s", d.Profile, strings.Join(UserProfiles, ", "))
+		}
+		u.Attributes[UserAttrProfile] = d.Profile
+	}
+	return nil
+}
+
+// CreateUser creates a new user from the passed definition.
+func CreateUser(def *UserDefinition) (*models.IdmUser, error) {
+	if def.Login == "" {
+		return nil, fmt.Errorf("login cannot be empty")
+	}
+	if def.Password == "" {
+		return nil, fmt.Errorf("a password is required to create user %s", def.Login)
+	}
+	if _, err := FindUser(def.Login); err == nil {
+		return nil, fmt.Errorf("user %s already exists", def.Login)
+	}
+	u := &models.IdmUser{Login: def.Login, GroupPath: "/"}
+	if def.Profile == "" {
+		def.Profile = UserProfiles[0]
+	}
+	if err := def.Apply(u); err != nil {
+		return nil, err
+	}
+	return SaveUser(u)
+}
+
+// UpdateUser applies the non-empty settings of the definition to an existing user.
+func UpdateUser(def *UserDefinition) (*models.IdmUser, error) {
+	u, err := FindUser(def.Login)
+	if err != nil {
+		return nil, err
+	}
+	if err = def.Apply(u); err != nil {
+		return nil, err
+	}
+	return SaveUser(u)
+}
+
+// SaveUser creates or updates the passed user.
+func SaveUser(u *models.IdmUser) (*models.IdmUser, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &user_service.PutUserParams{Login: u.Login, Body: u, Context: ctx}
+	res, err := client.UserService.PutUser(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not save user %s: %s", u.Login, err.Error())
+	}
+	return res.Payload, nil
+}
+
+// DeleteUser removes the user with the passed login.
+func DeleteUser(login string) error {
+	u, err := FindUser(login)
+	if err != nil {
+		return err
+	}
+	return deleteIdmNode(path.Join("/", u.GroupPath, u.Login))
+}
+
+// SetUserPassword changes the password of a user.
+func SetUserPassword(login, password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	_, err := UpdateUser(&UserDefinition{Login: login, Password: password})
+	return err
+}
+
+// SetUserLocked prevents (or allows again) the user from logging in.
+func SetUserLocked(login string, locked bool) error {
+	u, err := FindUser(login)
+	if err != nil {
+		return err
+	}
+	var locks []string
+	if v := u.Attributes[UserAttrLocks]; v != "" {
+		_ = json.Unmarshal([]byte(v), &locks)
+	}
+	var newLocks []string
+	for _, l := range locks {
+		if l != userLockLogout {
+			newLocks = append(newLocks, l)
+		}
+	}
+	if locked {
+		newLocks = append(newLocks, userLockLogout)
+	}
+	if u.Attributes == nil {
+		u.Attributes = map[string]string{}
+	}
+	if len(newLocks) == 0 {
+		delete(u.Attributes, UserAttrLocks)
+	} else {
+		data, _ := json.Marshal(newLocks)
+		u.Attributes[UserAttrLocks] = string(data)
+	}
+	_, err = SaveUser(u)
+	return err
+}
+
+// IsUserLocked checks if the user is prevented from logging in.
+func IsUserLocked(u *models.IdmUser) bool {
+	var locks []string
+	if err := json.Unmarshal([]byte(u.Attributes[UserAttrLocks]), &locks); err != nil {
+		return false
+	}
+	for _, l := range locks {
+		if l == userLockLogout {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidProfile(profile string) bool {
+	for _, p := range UserProfiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteIdmNode removes a user or a group given its full path.
+func deleteIdmNode(fullPath string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &user_service.DeleteUserParams{FullPath: strings.TrimLeft(fullPath, "/"), Context: ctx}
+	if _, err = client.UserService.DeleteUser(params); err != nil {
+		return fmt.Errorf("could not delete %s: %s", fullPath, err.Error())
+	}
+	return nil
+}
+
+// FindUser retrieves a user by its login.
+func FindUser(login string) (*models.IdmUser, error) {
+	res, err := searchUsers(&models.IdmUserSingleQuery{
+		Login:    login,
+		NodeType: models.NewIdmNodeType(models.IdmNodeTypeUSER),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not search user %s: %s", login, err.Error())
+	}
+	for _, u := range res.Users {
+		if u.Login == login {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("no user found with login %s", login)
+}
+
+// FindGroup retrieves a group by its full path, e.g. /engineering/backend.
+func FindGroup(groupPath string) (*models.IdmUser, error) {
+	groupPath = "/" + strings.Trim(groupPath, "/")
+	res, err := searchUsers(&models.IdmUserSingleQuery{
+		FullPath: groupPath,
+		NodeType: models.NewIdmNodeType(models.IdmNodeTypeGROUP),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not search group %s: %s", groupPath, err.Error())
+	}
+	for _, g := range res.Groups {
+		if GroupFullPath(g) == groupPath {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("no group found at %s", groupPath)
+}
+
+// GroupFullPath returns the full path of a group, e.g. /engineering/backend: for a group,
+// the GroupPath already contains its own label.
+func GroupFullPath(g *models.IdmUser) string {
+	return path.Join("/", g.GroupPath)
+}
+
+// searchUsers retrieves all users and groups that match the passed queries, walking through the result pages.
+func searchUsers(queries ...*models.IdmUserSingleQuery) (*models.RestUsersCollection, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	all := &models.RestUsersCollection{}
+	var offset int
+	for {
+		params := &user_service.SearchUsersParams{
+			Body: &models.RestSearchUserRequest{
+				Queries: queries,
+				Limit:   strconv.Itoa(listPageSize),
+				Offset:  strconv.Itoa(offset),
+			},
+			Context: ctx,
+		}
+		res, err := client.UserService.SearchUsers(params)
+		if err != nil {
+			return nil, err
+		}
+		all.Users = append(all.Users, res.Payload.Users...)
+		all.Groups = append(all.Groups, res.Payload.Groups...)
+		nb := len(res.Payload.Users) + len(res.Payload.Groups)
+		offset += nb
+		if nb < listPageSize {
+			break
+		}
+	}
+	return all, nil
+}
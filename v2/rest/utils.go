@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
 	"math/rand"
 	"net/url"
@@ -62,6 +64,56 @@ func RetrieveCurrentSessionLogin() (string, error) {
 	return "", fmt.Errorf("no <user> tag found in registry. Are you sure you are connected?")
 }
 
+// UserWorkspace is a workspace that is accessible to the current user, as described in the registry of the server.
+type UserWorkspace struct {
+	ID    string `json:"id" yaml:"id" xml:"id,attr"`
+	Slug  string `json:"slug" yaml:"slug" xml:"repositorySlug,attr"`
+	Label string `json:"label" yaml:"label" xml:"label"`
+	// ACL is r, w or rw.
+	ACL        string `json:"acl" yaml:"acl" xml:"acl,attr"`
+	AccessType string `json:"-" yaml:"-" xml:"access_type,attr"`
+}
+
+// Access types of the registry entries that are not data workspaces.
+var nonDataAccessTypes = map[string]bool{"settings": true, "homepage": true, "inbox": true}
+
+// RetrieveUserWorkspaces lists the workspaces that are accessible to the current user, with their permissions.
+func RetrieveUserWorkspaces() ([]UserWorkspace, error) {
+	resp, err := AuthenticatedGet("/a/frontend/state")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("could not retrieve the workspaces of the current user: received status code %d - %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var workspaces []UserWorkspace
+	decoder := xml.NewDecoder(resp.Body)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not parse the registry of the current user: %s", err.Error())
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok || se.Name.Local != "repo" {
+			continue
+		}
+		var ws UserWorkspace
+		if err = decoder.DecodeElement(&ws, &se); err != nil {
+			return nil, fmt.Errorf("could not decode workspace in registry: %s", err.Error())
+		}
+		if ws.Slug == "" || nonDataAccessTypes[ws.AccessType] || nonDataAccessTypes[ws.ID] {
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+	return workspaces, nil
+}
+
 // RetrieveRemoteServerVersion gets the version info from the distant server.
 // User must be authenticated (and admin ?).
 func RetrieveRemoteServerVersion() (*common.ServerVersion, error) {
@@ -107,6 +159,15 @@ func IsForbiddenError(err error) bool {
 	return false
 }
 
+// IsNotFoundError checks if the API reports that the requested object does not exist.
+func IsNotFoundError(err error) bool {
+	switch e := err.(type) {
+	case *runtime.APIError:
+		return e.Code == 404
+	}
+	return false
+}
+
 func StandardizeLink(old string) string {
 	if strings.HasPrefix(old, "/") && !strings.HasPrefix(old, "http") {
 		return DefaultConfig.Url + old
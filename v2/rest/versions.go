@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/pydio/cells-sdk-go/v3/client/meta_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+const (
+	// MetaVersionID is the key of the version ID in the MetaStore of the nodes returned when listing versions.
+	MetaVersionID = "versionId"
+	// MetaVersionDescription is the key of the version description, that typically also holds the author of the version.
+	MetaVersionDescription = "versionDescription"
+)
+
+// FileVersion holds the information about a single version of a file.
+type FileVersion struct {
+	ID          string
+	Description string
+	Size        string
+	MTime       string
+	ETag        string
+}
+
+// ListVersions retrieves the versions of the file at the passed path. Most recent versions come first.
+// Versions are only available for files that are stored in a versioned datasource.
+func ListVersions(nodePath string) ([]*FileVersion, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &meta_service.GetBulkMetaParams{
+		Body: &models.RestGetBulkMetaRequest{
+			NodePaths: []string{strings.Trim(nodePath, "/")},
+			Versions:  true,
+		},
+		Context: ctx,
+	}
+	res, err := client.MetaService.GetBulkMeta(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list versions of %s: %s", nodePath, err.Error())
+	}
+
+	var versions []*FileVersion
+	for _, n := range res.Payload.Nodes {
+		id := strings.Trim(n.MetaStore[MetaVersionID], "\"")
+		if id == "" {
+			continue
+		}
+		versions = append(versions, &FileVersion{
+			ID:          id,
+			Description: strings.Trim(n.MetaStore[MetaVersionDescription], "\""),
+			Size:        n.Size,
+			MTime:       n.MTime,
+			ETag:        n.Etag,
+		})
+	}
+	return versions, nil
+}
+
+// FindVersion retrieves a given version of the file at the passed path.
+func FindVersion(nodePath, versionID string) (*FileVersion, error) {
+	versions, err := ListVersions(nodePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.ID == versionID {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("no version %s found for %s", versionID, nodePath)
+}
+
+// RestoreVersion promotes an old version of a file as its current content:
+// the content of the version is uploaded again at the same path, that creates a new version.
+func RestoreVersion(nodePath, versionID string) error {
+	reader, _, err := GetFileVersion(nodePath, versionID)
+	if err != nil {
+		return fmt.Errorf("could not retrieve version %s of %s: %s", versionID, nodePath, err.Error())
+	}
+
+	s3Client, bucketName, err := GetS3Client()
+	if err != nil {
+		return err
+	}
+	sess, err := session.NewSession(&s3Client.Config)
+	if err != nil {
+		return err
+	}
+	sess.Config.S3DisableContentMD5Validation = aws.Bool(true)
+
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = 50 * 1024 * 1024
+		u.Concurrency = 3
+	})
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Body:   reader,
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(strings.Trim(nodePath, "/")),
+	})
+	if err != nil {
+		return fmt.Errorf("could not restore version %s of %s: %s", versionID, nodePath, err.Error())
+	}
+	return nil
+}
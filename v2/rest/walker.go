@@ -31,6 +31,8 @@ type CrawlNode struct {
 	MTime       time.Time
 	Size        int64
 	NewFileName string
+	// VersionID is only used for remote files, to download another version than the current one.
+	VersionID string
 
 	os.FileInfo
 	models.TreeNode
@@ -304,7 +306,7 @@ func (c *CrawlNode) upload(src *CrawlNode, bar *uiprogress.Bar) error {
 }
 
 func (c *CrawlNode) download(src *CrawlNode, bar *uiprogress.Bar) error {
-	reader, length, e := GetFile(src.FullPath)
+	reader, length, e := GetFileVersion(src.FullPath, src.VersionID)
 	if e != nil {
 		return e
 	}
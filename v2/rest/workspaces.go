@@ -0,0 +1,238 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pydio/cells-sdk-go/v3/client/acl_service"
+	"github.com/pydio/cells-sdk-go/v3/client/admin_tree_service"
+	"github.com/pydio/cells-sdk-go/v3/client/config_service"
+	"github.com/pydio/cells-sdk-go/v3/client/workspace_service"
+	"github.com/pydio/cells-sdk-go/v3/models"
+)
+
+// FindWorkspace retrieves a workspace given its slug or its UUID.
+func FindWorkspace(id string) (*models.IdmWorkspace, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &workspace_service.SearchWorkspacesParams{
+		Body: &models.RestSearchWorkspaceRequest{
+			Queries: []*models.IdmWorkspaceSingleQuery{{Slug: id}, {UUID: id}},
+		},
+		Context: ctx,
+	}
+	res, err := client.WorkspaceService.SearchWorkspaces(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not search workspace %s: %s", id, err.Error())
+	}
+	for _, ws := range res.Payload.Workspaces {
+		if ws.Slug == id || ws.UUID == id {
+			return ws, nil
+		}
+	}
+	return nil, fmt.Errorf("no workspace found with slug or ID %s", id)
+}
+
+// Keys of the workspace attributes that are managed by the client.
+const (
+	WsAttrDefaultRights = "DEFAULT_RIGHTS"
+	WsAttrAllowSync     = "ALLOW_SYNC"
+)
+
+// RootGroupRole is the role of the root group: rights granted to this role apply to all users.
+const RootGroupRole = "ROOT_GROUP"
+
+// ResolveWorkspaceRoot finds the node that is designated by a workspace root definition:
+// either the ID of a template path (e.g. my-files) or a path in the admin tree (e.g. pydiods1/folder).
+func ResolveWorkspaceRoot(root string) (*models.TreeNode, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	root = strings.Trim(root, "/")
+
+	templates, err := client.ConfigService.ListVirtualNodes(&config_service.ListVirtualNodesParams{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("could not list template paths: %s", err.Error())
+	}
+	for _, n := range templates.Payload.Children {
+		if n.UUID == root || strings.Trim(n.Path, "/") == root {
+			return &models.TreeNode{UUID: n.UUID, Path: n.Path}, nil
+		}
+	}
+
+	params := &admin_tree_service.StatAdminTreeParams{
+		Body:    &models.TreeReadNodeRequest{Node: &models.TreeNode{Path: root}},
+		Context: ctx,
+	}
+	res, err := client.AdminTreeService.StatAdminTree(params)
+	if err != nil || res.Payload.Node == nil {
+		return nil, fmt.Errorf("no template path nor datasource folder found for %s", root)
+	}
+	return res.Payload.Node, nil
+}
+
+// WorkspaceAttributes parses the JSON attributes of a workspace.
+func WorkspaceAttributes(ws *models.IdmWorkspace) map[string]interface{} {
+	attrs := map[string]interface{}{}
+	if ws.Attributes != "" {
+		_ = json.Unmarshal([]byte(ws.Attributes), &attrs)
+	}
+	return attrs
+}
+
+// SetWorkspaceAttribute updates a single attribute of the workspace.
+func SetWorkspaceAttribute(ws *models.IdmWorkspace, key string, value interface{}) {
+	attrs := WorkspaceAttributes(ws)
+	attrs[key] = value
+	data, _ := json.Marshal(attrs)
+	ws.Attributes = string(data)
+}
+
+// SetWorkspaceRoots replaces the roots of the workspace with the nodes designated by the passed definitions.
+func SetWorkspaceRoots(ws *models.IdmWorkspace, roots []string) error {
+	ws.RootUUIDs = nil
+	ws.RootNodes = map[string]models.TreeNode{}
+	for _, r := range roots {
+		node, err := ResolveWorkspaceRoot(r)
+		if err != nil {
+			return err
+		}
+		ws.RootUUIDs = append(ws.RootUUIDs, node.UUID)
+		ws.RootNodes[node.UUID] = *node
+	}
+	return nil
+}
+
+// SaveWorkspace creates or updates a workspace. If defaultRights is not nil, the rights
+// that are granted to all users on the workspace are also updated.
+func SaveWorkspace(ws *models.IdmWorkspace, defaultRights *string) (*models.IdmWorkspace, error) {
+	if ws.Slug == "" {
+		return nil, fmt.Errorf("the slug of the workspace cannot be empty")
+	}
+	if defaultRights != nil {
+		SetWorkspaceAttribute(ws, WsAttrDefaultRights, *defaultRights)
+	}
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &workspace_service.PutWorkspaceParams{Slug: ws.Slug, Body: ws, Context: ctx}
+	res, err := client.WorkspaceService.PutWorkspace(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not save workspace %s: %s", ws.Slug, err.Error())
+	}
+	saved := res.Payload
+	if defaultRights != nil {
+		if err = SetWorkspaceRights(RootGroupRole, saved.UUID, *defaultRights); err != nil {
+			return saved, fmt.Errorf("workspace has been saved but default rights could not be applied: %s", err.Error())
+		}
+	}
+	return saved, nil
+}
+
+// DeleteWorkspace removes a workspace. The data of its roots are left untouched.
+func DeleteWorkspace(slug string) error {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	params := &workspace_service.DeleteWorkspaceParams{Slug: slug, Context: ctx}
+	if _, err = client.WorkspaceService.DeleteWorkspace(params); err != nil {
+		return fmt.Errorf("could not delete workspace %s: %s", slug, err.Error())
+	}
+	return nil
+}
+
+// ListWorkspaceAcls retrieves all ACLs that are defined on the workspace.
+func ListWorkspaceAcls(workspaceUUID string) ([]*models.IdmACL, error) {
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return nil, err
+	}
+	params := &acl_service.SearchAclsParams{
+		Body: &models.RestSearchACLRequest{
+			Queries: []*models.IdmACLSingleQuery{{WorkspaceIDs: []string{workspaceUUID}}},
+		},
+		Context: ctx,
+	}
+	res, err := client.ACLService.SearchAcls(params)
+	if err != nil {
+		return nil, fmt.Errorf("could not list ACLs of workspace %s: %s", workspaceUUID, err.Error())
+	}
+	return res.Payload.ACLs, nil
+}
+
+// MoveWorkspaceAcls is used after the roots of a workspace have changed: the ACLs that are defined on the old roots
+// are re-created on the new roots, and then removed from the old roots, so that roles keep their access.
+// ACLs that are defined on other nodes of the workspace are left untouched.
+func MoveWorkspaceAcls(ws *models.IdmWorkspace, oldRootUUIDs []string) error {
+	newRoots := map[string]bool{}
+	for _, id := range ws.RootUUIDs {
+		newRoots[id] = true
+	}
+	previous := map[string]bool{}
+	oldRoots := map[string]bool{}
+	for _, id := range oldRootUUIDs {
+		previous[id] = true
+		if !newRoots[id] {
+			oldRoots[id] = true
+		}
+	}
+	if len(oldRoots) == 0 {
+		return nil
+	}
+
+	acls, err := ListWorkspaceAcls(ws.UUID)
+	if err != nil {
+		return err
+	}
+	// The same rights are usually granted on all roots: only copy them once
+	type grant struct{ roleID, action, value string }
+	var grants []grant
+	seen := map[grant]bool{}
+	var obsolete []*models.IdmACL
+	for _, acl := range acls {
+		if !oldRoots[acl.NodeID] || acl.Action == nil {
+			continue
+		}
+		obsolete = append(obsolete, acl)
+		g := grant{roleID: acl.RoleID, action: acl.Action.Name, value: acl.Action.Value}
+		if !seen[g] {
+			seen[g] = true
+			grants = append(grants, g)
+		}
+	}
+
+	ctx, client, err := GetApiClient()
+	if err != nil {
+		return err
+	}
+	for _, id := range ws.RootUUIDs {
+		if previous[id] {
+			// Kept roots already have their ACLs
+			continue
+		}
+		for _, g := range grants {
+			acl := &models.IdmACL{
+				Action:      &models.IdmACLAction{Name: g.action, Value: g.value},
+				RoleID:      g.roleID,
+				WorkspaceID: ws.UUID,
+				NodeID:      id,
+			}
+			if _, err = client.ACLService.PutACL(&acl_service.PutACLParams{Body: acl, Context: ctx}); err != nil {
+				return fmt.Errorf("could not grant %s to role %s on the new root %s: %s", g.action, g.roleID, id, err.Error())
+			}
+		}
+	}
+	// Only remove the old ACLs once all new ones have been created
+	for _, acl := range obsolete {
+		if _, err = client.ACLService.DeleteACL(&acl_service.DeleteACLParams{Body: acl, Context: ctx}); err != nil {
+			return fmt.Errorf("could not remove ACL %s of the old root %s: %s", acl.ID, acl.NodeID, err.Error())
+		}
+	}
+	return nil
+}